@@ -0,0 +1,467 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import (
+	"io/fs"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CacheType is the type of a cache.
+type CacheType uint8
+
+// Cache types, with the values used by CPUID leaf 4 and 0x8000001D.
+const (
+	CacheTypeUnknown     CacheType = 0
+	CacheTypeData        CacheType = 1
+	CacheTypeInstruction CacheType = 2
+	CacheTypeUnified     CacheType = 3
+)
+
+// String returns the name of the cache type.
+func (t CacheType) String() string {
+	switch t {
+	case CacheTypeData:
+		return "Data"
+	case CacheTypeInstruction:
+		return "Instruction"
+	case CacheTypeUnified:
+		return "Unified"
+	}
+	return "Unknown"
+}
+
+// CacheInfo describes a cache.
+// Fields are 0 if unknown.
+type CacheInfo struct {
+	Level            int
+	Type             CacheType
+	Size             int  // Size in bytes
+	Ways             int  // Associativity. Equal to the number of lines if fully associative.
+	FullyAssociative bool // Any line can be stored anywhere in the cache
+	Sets             int
+	LineSize         int // Line size in bytes
+	Partitions       int // Physical line partitions
+	SharedBy         int // Maximum number of logical CPUs sharing each instance of the cache
+	Inclusive        bool
+	// WriteBack is set when WBINVD and INVD are not guaranteed to write back and
+	// invalidate lower level caches of other logical CPUs sharing this cache.
+	WriteBack       bool
+	ComplexIndexing bool // A complex function is used to index the cache
+}
+
+// cacheSize will fill Caches and the sizes in Cache.
+func (c *CPUInfo) cacheSize() {
+	c.Cache.L1D = -1
+	c.Cache.L1I = -1
+	c.Cache.L2 = -1
+	c.Cache.L3 = -1
+	var caches []CacheInfo
+	switch c.VendorID {
+	case Intel, VIA, Zhaoxin:
+		if c.maxFunc >= 4 {
+			caches = c.cachesDeterministic(4)
+		}
+		if len(caches) > 0 {
+			c.Cache.L1I, c.Cache.L1D, c.Cache.L2, c.Cache.L3 = 0, 0, 0, 0
+			break
+		}
+		if c.VendorID == Intel {
+			caches = c.cachesLeaf2()
+		} else {
+			caches = c.cachesCentaur()
+		}
+	case AMD, Hygon:
+		if c.maxExFunc >= 0x8000001D && c.Has(TOPEXT) {
+			caches = c.cachesDeterministic(0x8000001D)
+		}
+		if len(caches) == 0 {
+			caches = c.cachesAMDLegacy()
+		}
+	}
+	c.setCaches(caches)
+}
+
+// osCachesOnce reads the caches reported by the OS once,
+// so detecting the CPU again does not read them again.
+var osCachesOnce = sync.OnceValue(osCaches)
+
+// setCaches sets Caches, and the sizes in Cache from them.
+// The cache line size is set from the L1 data cache if unknown.
+func (c *CPUInfo) setCaches(caches []CacheInfo) {
+	c.Caches = caches
+	for _, ci := range caches {
+		switch ci.Level {
+		case 1:
+			switch ci.Type {
+			case CacheTypeData:
+				c.Cache.L1D = ci.Size
+				if c.CacheLine == 0 {
+					c.CacheLine = ci.LineSize
+				}
+			case CacheTypeInstruction:
+				c.Cache.L1I = ci.Size
+			default:
+				if c.Cache.L1I < 0 {
+					c.Cache.L1I = ci.Size
+				}
+			}
+		case 2:
+			c.Cache.L2 = ci.Size
+		case 3:
+			c.Cache.L3 = ci.Size
+		}
+	}
+}
+
+// cachesDeterministic reads the caches from the deterministic cache parameters
+// of Intel leaf 4 or AMD leaf 0x8000001D, which use the same layout.
+func (c *CPUInfo) cachesDeterministic(leaf uint32) []CacheInfo {
+	var caches []CacheInfo
+	var last [4]uint32
+	for i := uint32(0); i < math.MaxUint32; i++ {
+		eax, ebx, ecx, edx := c.src.CPUIDEX(leaf, i)
+		typ := CacheType(eax & 15)
+		if typ == CacheTypeUnknown {
+			break
+		}
+		// Xen Hypervisor is buggy and returns the same entry no matter ECX value.
+		// Entries include the level and type, so a repeated entry ends the list.
+		if regs := [4]uint32{eax, ebx, ecx, edx}; regs == last {
+			break
+		} else {
+			last = regs
+		}
+
+		ci := CacheInfo{
+			Level:            int(eax>>5) & 7,
+			Type:             typ,
+			FullyAssociative: eax&(1<<9) != 0,
+			SharedBy:         int(eax>>14)&0xfff + 1,
+			LineSize:         int(ebx&0xfff) + 1,
+			Partitions:       int(ebx>>12)&0x3ff + 1,
+			Ways:             int(ebx>>22)&0x3ff + 1,
+			Sets:             int(ecx) + 1,
+			WriteBack:        edx&1 != 0,
+			Inclusive:        edx&2 != 0,
+		}
+		if leaf == 4 {
+			ci.ComplexIndexing = edx&4 != 0
+		}
+		ci.Size = ci.Ways * ci.Partitions * ci.LineSize * ci.Sets
+		caches = append(caches, ci)
+	}
+	return caches
+}
+
+// cachesLeaf2 reads the caches from the leaf 2 descriptors.
+func (c *CPUInfo) cachesLeaf2() []CacheInfo {
+	var caches []CacheInfo
+	for _, d := range c.leaf2Descriptors() {
+		ci, ok := leaf2Caches[d]
+		if !ok {
+			continue
+		}
+		if d == 0x49 && c.Family == 0xf && c.Model == 6 {
+			// Xeon MP family 0xf model 6 has an L3 instead of an L2.
+			ci.Level = 3
+		}
+		ci.Partitions = 1
+		ci.Sets = ci.Size / (ci.Ways * ci.LineSize)
+		caches = append(caches, ci)
+	}
+	sort.SliceStable(caches, func(i, j int) bool { return caches[i].Level < caches[j].Level })
+	return caches
+}
+
+// leaf2Caches contains the caches of the leaf 2 descriptors.
+// Trace caches, which are measured in µops, are not included.
+var leaf2Caches = map[byte]CacheInfo{
+	0x06: {Level: 1, Type: CacheTypeInstruction, Size: 8 << 10, Ways: 4, LineSize: 32},
+	0x08: {Level: 1, Type: CacheTypeInstruction, Size: 16 << 10, Ways: 4, LineSize: 32},
+	0x09: {Level: 1, Type: CacheTypeInstruction, Size: 32 << 10, Ways: 4, LineSize: 64},
+	0x0a: {Level: 1, Type: CacheTypeData, Size: 8 << 10, Ways: 2, LineSize: 32},
+	0x0c: {Level: 1, Type: CacheTypeData, Size: 16 << 10, Ways: 4, LineSize: 32},
+	0x0d: {Level: 1, Type: CacheTypeData, Size: 16 << 10, Ways: 4, LineSize: 64},
+	0x0e: {Level: 1, Type: CacheTypeData, Size: 24 << 10, Ways: 6, LineSize: 64},
+	0x1d: {Level: 2, Type: CacheTypeUnified, Size: 128 << 10, Ways: 2, LineSize: 64},
+	0x21: {Level: 2, Type: CacheTypeUnified, Size: 256 << 10, Ways: 8, LineSize: 64},
+	0x22: {Level: 3, Type: CacheTypeUnified, Size: 512 << 10, Ways: 4, LineSize: 64},
+	0x23: {Level: 3, Type: CacheTypeUnified, Size: 1 << 20, Ways: 8, LineSize: 64},
+	0x24: {Level: 2, Type: CacheTypeUnified, Size: 1 << 20, Ways: 16, LineSize: 64},
+	0x25: {Level: 3, Type: CacheTypeUnified, Size: 2 << 20, Ways: 8, LineSize: 64},
+	0x29: {Level: 3, Type: CacheTypeUnified, Size: 4 << 20, Ways: 8, LineSize: 64},
+	0x2c: {Level: 1, Type: CacheTypeData, Size: 32 << 10, Ways: 8, LineSize: 64},
+	0x30: {Level: 1, Type: CacheTypeInstruction, Size: 32 << 10, Ways: 8, LineSize: 64},
+	0x39: {Level: 2, Type: CacheTypeUnified, Size: 128 << 10, Ways: 4, LineSize: 64},
+	0x3a: {Level: 2, Type: CacheTypeUnified, Size: 192 << 10, Ways: 6, LineSize: 64},
+	0x3b: {Level: 2, Type: CacheTypeUnified, Size: 128 << 10, Ways: 2, LineSize: 64},
+	0x3c: {Level: 2, Type: CacheTypeUnified, Size: 256 << 10, Ways: 4, LineSize: 64},
+	0x3d: {Level: 2, Type: CacheTypeUnified, Size: 384 << 10, Ways: 6, LineSize: 64},
+	0x3e: {Level: 2, Type: CacheTypeUnified, Size: 512 << 10, Ways: 4, LineSize: 64},
+	0x3f: {Level: 2, Type: CacheTypeUnified, Size: 256 << 10, Ways: 2, LineSize: 64},
+	0x41: {Level: 2, Type: CacheTypeUnified, Size: 128 << 10, Ways: 4, LineSize: 32},
+	0x42: {Level: 2, Type: CacheTypeUnified, Size: 256 << 10, Ways: 4, LineSize: 32},
+	0x43: {Level: 2, Type: CacheTypeUnified, Size: 512 << 10, Ways: 4, LineSize: 32},
+	0x44: {Level: 2, Type: CacheTypeUnified, Size: 1 << 20, Ways: 4, LineSize: 32},
+	0x45: {Level: 2, Type: CacheTypeUnified, Size: 2 << 20, Ways: 4, LineSize: 32},
+	0x46: {Level: 3, Type: CacheTypeUnified, Size: 4 << 20, Ways: 4, LineSize: 64},
+	0x47: {Level: 3, Type: CacheTypeUnified, Size: 8 << 20, Ways: 8, LineSize: 64},
+	0x48: {Level: 2, Type: CacheTypeUnified, Size: 3 << 20, Ways: 12, LineSize: 64},
+	0x49: {Level: 2, Type: CacheTypeUnified, Size: 4 << 20, Ways: 16, LineSize: 64},
+	0x4a: {Level: 3, Type: CacheTypeUnified, Size: 6 << 20, Ways: 12, LineSize: 64},
+	0x4b: {Level: 3, Type: CacheTypeUnified, Size: 8 << 20, Ways: 16, LineSize: 64},
+	0x4c: {Level: 3, Type: CacheTypeUnified, Size: 12 << 20, Ways: 12, LineSize: 64},
+	0x4d: {Level: 3, Type: CacheTypeUnified, Size: 16 << 20, Ways: 16, LineSize: 64},
+	0x4e: {Level: 2, Type: CacheTypeUnified, Size: 6 << 20, Ways: 24, LineSize: 64},
+	0x60: {Level: 1, Type: CacheTypeData, Size: 16 << 10, Ways: 8, LineSize: 64},
+	0x66: {Level: 1, Type: CacheTypeData, Size: 8 << 10, Ways: 4, LineSize: 64},
+	0x67: {Level: 1, Type: CacheTypeData, Size: 16 << 10, Ways: 4, LineSize: 64},
+	0x68: {Level: 1, Type: CacheTypeData, Size: 32 << 10, Ways: 4, LineSize: 64},
+	0x78: {Level: 2, Type: CacheTypeUnified, Size: 1 << 20, Ways: 4, LineSize: 64},
+	0x79: {Level: 2, Type: CacheTypeUnified, Size: 128 << 10, Ways: 8, LineSize: 64},
+	0x7a: {Level: 2, Type: CacheTypeUnified, Size: 256 << 10, Ways: 8, LineSize: 64},
+	0x7b: {Level: 2, Type: CacheTypeUnified, Size: 512 << 10, Ways: 8, LineSize: 64},
+	0x7c: {Level: 2, Type: CacheTypeUnified, Size: 1 << 20, Ways: 8, LineSize: 64},
+	0x7d: {Level: 2, Type: CacheTypeUnified, Size: 2 << 20, Ways: 8, LineSize: 64},
+	0x7f: {Level: 2, Type: CacheTypeUnified, Size: 512 << 10, Ways: 2, LineSize: 64},
+	0x80: {Level: 2, Type: CacheTypeUnified, Size: 512 << 10, Ways: 8, LineSize: 64},
+	0x82: {Level: 2, Type: CacheTypeUnified, Size: 256 << 10, Ways: 8, LineSize: 32},
+	0x83: {Level: 2, Type: CacheTypeUnified, Size: 512 << 10, Ways: 8, LineSize: 32},
+	0x84: {Level: 2, Type: CacheTypeUnified, Size: 1 << 20, Ways: 8, LineSize: 32},
+	0x85: {Level: 2, Type: CacheTypeUnified, Size: 2 << 20, Ways: 8, LineSize: 32},
+	0x86: {Level: 2, Type: CacheTypeUnified, Size: 512 << 10, Ways: 4, LineSize: 64},
+	0x87: {Level: 2, Type: CacheTypeUnified, Size: 1 << 20, Ways: 8, LineSize: 64},
+	0xd0: {Level: 3, Type: CacheTypeUnified, Size: 512 << 10, Ways: 4, LineSize: 64},
+	0xd1: {Level: 3, Type: CacheTypeUnified, Size: 1 << 20, Ways: 4, LineSize: 64},
+	0xd2: {Level: 3, Type: CacheTypeUnified, Size: 2 << 20, Ways: 4, LineSize: 64},
+	0xd6: {Level: 3, Type: CacheTypeUnified, Size: 1 << 20, Ways: 8, LineSize: 64},
+	0xd7: {Level: 3, Type: CacheTypeUnified, Size: 2 << 20, Ways: 8, LineSize: 64},
+	0xd8: {Level: 3, Type: CacheTypeUnified, Size: 4 << 20, Ways: 8, LineSize: 64},
+	0xdc: {Level: 3, Type: CacheTypeUnified, Size: 1536 << 10, Ways: 12, LineSize: 64},
+	0xdd: {Level: 3, Type: CacheTypeUnified, Size: 3 << 20, Ways: 12, LineSize: 64},
+	0xde: {Level: 3, Type: CacheTypeUnified, Size: 6 << 20, Ways: 12, LineSize: 64},
+	0xe2: {Level: 3, Type: CacheTypeUnified, Size: 2 << 20, Ways: 16, LineSize: 64},
+	0xe3: {Level: 3, Type: CacheTypeUnified, Size: 4 << 20, Ways: 16, LineSize: 64},
+	0xe4: {Level: 3, Type: CacheTypeUnified, Size: 8 << 20, Ways: 16, LineSize: 64},
+	0xea: {Level: 3, Type: CacheTypeUnified, Size: 12 << 20, Ways: 24, LineSize: 64},
+	0xeb: {Level: 3, Type: CacheTypeUnified, Size: 18 << 20, Ways: 24, LineSize: 64},
+	0xec: {Level: 3, Type: CacheTypeUnified, Size: 24 << 20, Ways: 24, LineSize: 64},
+}
+
+// amdAssociativity contains the ways of the 4 bit associativity encoding of
+// AMD leaf 0x80000006. 0 is disabled, -1 is fully associative and
+// -2 means that the value must be read from leaf 0x8000001D.
+// If leaf 0x8000001D is not available, Ways and Sets of these caches are 0.
+var amdAssociativity = [16]int{0, 1, 2, 3, 4, 6, 8, 0, 16, -2, 32, 48, 64, 96, 128, -1}
+
+// l1Ways returns the ways of the L1 associativity of leaf 0x80000005.
+// 0xff is fully associative and returned as -1.
+func l1Ways(v uint32) int {
+	if v == 0xff {
+		return -1
+	}
+	return int(v)
+}
+
+// legacyCache returns a cache read from leaf 0x80000005 or 0x80000006.
+// ways is -1 if fully associative. Other negative values and 0 are unknown.
+func (c *CPUInfo) legacyCache(level int, typ CacheType, size, ways, lineSize int) CacheInfo {
+	ci := CacheInfo{Level: level, Type: typ, Size: size, LineSize: lineSize, Partitions: 1}
+	if level < 3 {
+		ci.SharedBy = c.ThreadsPerCore
+	}
+	switch {
+	case ways == -1:
+		ci.FullyAssociative = true
+		if lineSize > 0 {
+			ci.Ways, ci.Sets = size/lineSize, 1
+		}
+	case ways > 0:
+		ci.Ways = ways
+		// The L3 reported by K10 may be reduced by HT Assist, so sets are not always whole.
+		if lineSize > 0 && size%(ways*lineSize) == 0 {
+			ci.Sets = size / (ways * lineSize)
+		}
+	}
+	return ci
+}
+
+// cachesAMDLegacy reads the caches from AMD leaf 0x80000005 and 0x80000006.
+func (c *CPUInfo) cachesAMDLegacy() []CacheInfo {
+	var caches []CacheInfo
+	add := func(level int, typ CacheType, size, ways, lineSize int) {
+		// Ways are 0 if the cache is disabled.
+		if size > 0 && ways != 0 {
+			caches = append(caches, c.legacyCache(level, typ, size, ways, lineSize))
+		}
+	}
+	if c.maxExFunc < 0x80000005 {
+		return nil
+	}
+	_, _, ecx, edx := c.src.CPUID(0x80000005)
+	add(1, CacheTypeData, int(ecx>>24)*1024, l1Ways((ecx>>16)&0xff), int(ecx&0xff))
+	add(1, CacheTypeInstruction, int(edx>>24)*1024, l1Ways((edx>>16)&0xff), int(edx&0xff))
+
+	if c.maxExFunc < 0x80000006 {
+		return caches
+	}
+	_, _, ecx, edx = c.src.CPUID(0x80000006)
+	add(2, CacheTypeUnified, int(ecx>>16)*1024, amdAssociativity[(ecx>>12)&0xf], int(ecx&0xff))
+	add(3, CacheTypeUnified, int(edx>>18)*512*1024, amdAssociativity[(edx>>12)&0xf], int(edx&0xff))
+
+	// Read unknown associativity from leaf 0x8000001D.
+	if c.maxExFunc >= 0x8000001d {
+		det := c.cachesDeterministic(0x8000001d)
+		for i, ci := range caches {
+			if ci.Ways != 0 || ci.FullyAssociative {
+				continue
+			}
+			for _, d := range det {
+				if d.Level == ci.Level && d.Type == ci.Type {
+					caches[i].Ways, caches[i].Sets, caches[i].Partitions = d.Ways, d.Sets, d.Partitions
+					break
+				}
+			}
+		}
+	}
+	return caches
+}
+
+// cachesCentaur reads the caches from leaf 0x80000005 and 0x80000006 of VIA and Zhaoxin CPUs.
+// The layout is the same as on AMD, except for the L2 of some models, and no L3 is reported.
+func (c *CPUInfo) cachesCentaur() []CacheInfo {
+	var caches []CacheInfo
+	add := func(level int, typ CacheType, size, ways, lineSize int) {
+		if size > 0 {
+			caches = append(caches, c.legacyCache(level, typ, size, max(ways, 0), lineSize))
+		}
+	}
+	if c.maxExFunc < 0x80000005 {
+		return nil
+	}
+	_, _, ecx, edx := c.src.CPUID(0x80000005)
+	add(1, CacheTypeData, int(ecx>>24)*1024, l1Ways((ecx>>16)&0xff), int(ecx&0xff))
+	add(1, CacheTypeInstruction, int(edx>>24)*1024, l1Ways((edx>>16)&0xff), int(edx&0xff))
+
+	if c.maxExFunc < 0x80000006 {
+		return caches
+	}
+	_, _, ecx, _ = c.src.CPUID(0x80000006)
+	switch {
+	case c.Family == 6 && (c.Model == 7 || c.Model == 8):
+		// C3 Samuel 2 and Ezra use the L1 layout.
+		add(2, CacheTypeUnified, int(ecx>>24)*1024, l1Ways((ecx>>16)&0xff), int(ecx&0xff))
+	default:
+		size := int(ecx >> 16)
+		if c.Family == 6 && c.Model == 9 && c.Stepping == 1 && size == 65 {
+			// Nehemiah stepping 1 reports 65KB.
+			size = 64
+		}
+		add(2, CacheTypeUnified, size*1024, amdAssociativity[(ecx>>12)&0xf], int(ecx&0xff))
+	}
+	return caches
+}
+
+// sysfsCaches reads the caches from a Linux sysfs cache directory,
+// like /sys/devices/system/cpu/cpu0/cache.
+func sysfsCaches(fsys fs.FS) []CacheInfo {
+	dirs, _ := fs.Glob(fsys, "index[0-9]*")
+	index := func(dir string) int {
+		n, _ := strconv.Atoi(strings.TrimPrefix(dir, "index"))
+		return n
+	}
+	sort.Slice(dirs, func(i, j int) bool { return index(dirs[i]) < index(dirs[j]) })
+	var caches []CacheInfo
+	for _, dir := range dirs {
+		read := func(name string) string {
+			b, err := fs.ReadFile(fsys, dir+"/"+name)
+			if err != nil {
+				return ""
+			}
+			return strings.TrimSpace(string(b))
+		}
+		readInt := func(name string) int {
+			n, _ := strconv.Atoi(read(name))
+			return n
+		}
+		ci := CacheInfo{
+			Level:      readInt("level"),
+			Ways:       readInt("ways_of_associativity"),
+			Sets:       readInt("number_of_sets"),
+			LineSize:   readInt("coherency_line_size"),
+			Partitions: readInt("physical_line_partition"),
+			SharedBy:   cpuListLen(read("shared_cpu_list")),
+			Size:       parseSysfsSize(read("size")),
+		}
+		switch read("type") {
+		case "Data":
+			ci.Type = CacheTypeData
+		case "Instruction":
+			ci.Type = CacheTypeInstruction
+		case "Unified":
+			ci.Type = CacheTypeUnified
+		}
+		if ci.Size == 0 {
+			ci.Size = ci.Ways * ci.Sets * ci.LineSize * max(ci.Partitions, 1)
+		}
+		if ci.Level > 0 && ci.Type != CacheTypeUnknown && ci.Size > 0 {
+			caches = append(caches, ci)
+		}
+	}
+	return caches
+}
+
+// parseSysfsSize parses a size like "32K" and returns it in bytes.
+func parseSysfsSize(s string) int {
+	shift := 0
+	switch {
+	case strings.HasSuffix(s, "K"):
+		shift = 10
+	case strings.HasSuffix(s, "M"):
+		shift = 20
+	case strings.HasSuffix(s, "G"):
+		shift = 30
+	}
+	if shift > 0 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n << shift
+}
+
+// cpuListLen returns the number of CPUs in a list like "0-3,8".
+// 0 is returned if the list is invalid.
+func cpuListLen(s string) int {
+	return len(cpuList(s))
+}
+
+// cpuList returns the CPUs in a list like "0-3,8".
+// Nil is returned if the list is invalid.
+func cpuList(s string) []int {
+	var cpus []int
+	for _, r := range strings.Split(s, ",") {
+		lo, hi, isRange := strings.Cut(r, "-")
+		a, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil
+		}
+		b := a
+		if isRange {
+			if b, err = strconv.Atoi(hi); err != nil || b < a {
+				return nil
+			}
+		}
+		for i := a; i <= b; i++ {
+			cpus = append(cpus, i)
+		}
+	}
+	return cpus
+}
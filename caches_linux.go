@@ -0,0 +1,10 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import "os"
+
+// osCaches returns the caches of the first CPU reported by the OS.
+func osCaches() []CacheInfo {
+	return sysfsCaches(os.DirFS("/sys/devices/system/cpu/cpu0/cache"))
+}
@@ -0,0 +1,11 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+//go:build !linux
+// +build !linux
+
+package cpuid
+
+// osCaches returns the caches of the first CPU reported by the OS.
+func osCaches() []CacheInfo {
+	return nil
+}
@@ -23,9 +23,21 @@ import (
 
 var js = flag.Bool("json", false, "Output as JSON")
 var level = flag.Int("check-level", 0, "Check microarchitecture level. Exit code will be 0 if supported")
+var explain = flag.Bool("explain", false, "List detected features with descriptions and where they are detected from")
+var cpus = flag.Bool("cpus", false, "List each logical CPU by running CPUID on it. Linux only")
 
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options]\n       %s dump [file]\n\nOptions:\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	cpuid.Flags()
 	flag.Parse()
+	cpuid.Detect()
+	if flag.Arg(0) == "dump" {
+		dump(flag.Arg(1))
+		return
+	}
 	if level != nil && *level > 0 {
 		if *level < 1 || *level > 4 {
 			log.Fatalln("Supply CPU level 1-4 to test as argument")
@@ -39,16 +51,7 @@ func main() {
 		os.Exit(0)
 	}
 	if *js {
-		info := struct {
-			cpuid.CPUInfo
-			Features []string
-			X64Level int
-		}{
-			CPUInfo:  cpuid.CPU,
-			Features: cpuid.CPU.FeatureSet(),
-			X64Level: cpuid.CPU.X64Level(),
-		}
-		b, err := json.MarshalIndent(info, "", "  ")
+		b, err := json.MarshalIndent(cpuid.CPU, "", "  ")
 		if err != nil {
 			panic(err)
 		}
@@ -56,6 +59,49 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *explain {
+		for _, f := range cpuid.CPU.Features().IDs() {
+			info := f.Info()
+			var origins []string
+			for _, o := range info.Origins {
+				origins = append(origins, o.String())
+			}
+			fmt.Printf("%-20s %-15s %s", info.Name, info.Category, info.Description)
+			if len(origins) > 0 {
+				fmt.Printf(" [%s]", strings.Join(origins, ", "))
+			}
+			fmt.Println()
+		}
+		os.Exit(0)
+	}
+
+	if *cpus {
+		list, err := cpuid.EnumerateCPUs()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		for _, l := range list {
+			if !l.Available {
+				fmt.Printf("CPU %d: not available\n", l.CPU)
+				continue
+			}
+			fmt.Printf("CPU %d: x2APIC ID %d", l.CPU, l.X2APICID)
+			for _, lvl := range l.Topology.Levels {
+				id, _ := l.ID(lvl.Type)
+				fmt.Printf(", %s %d", lvl.Type, id)
+			}
+			if l.CoreType != cpuid.CoreTypeUnknown {
+				fmt.Printf(", %s core", l.CoreType)
+			}
+			fmt.Printf(", cache IDs %v", l.CacheIDs)
+			if l.MissingFeatures.Len() > 0 {
+				fmt.Printf(", missing %s", l.MissingFeatures)
+			}
+			fmt.Println()
+		}
+		os.Exit(0)
+	}
+
 	fmt.Println("Name:", cpuid.CPU.BrandName)
 	fmt.Println("Vendor String:", cpuid.CPU.VendorString)
 	fmt.Println("Vendor ID:", cpuid.CPU.VendorID)
@@ -64,6 +110,17 @@ func main() {
 	fmt.Println("Logical Cores:", cpuid.CPU.LogicalCores)
 	fmt.Println("CPU Family", cpuid.CPU.Family, "Model:", cpuid.CPU.Model, "Stepping:", cpuid.CPU.Stepping)
 	fmt.Println("Features:", strings.Join(cpuid.CPU.FeatureSet(), ","))
+	if cpuid.CPU.Partial {
+		fmt.Println("Partial detection: only features known by golang.org/x/sys/cpu are detected")
+	}
+	if m := cpuid.CPU.Microarch(); m.Codename != "" {
+		fmt.Println("Microarchitecture:", m)
+	} else if core := cpuid.CPU.CoreName(); core != "" {
+		fmt.Println("Core:", core)
+	}
+	if cpuid.CPU.Variant != 0 || cpuid.CPU.Revision != 0 {
+		fmt.Println("Variant:", cpuid.CPU.Variant, "Revision:", cpuid.CPU.Revision)
+	}
 	fmt.Println("Microarchitecture level:", cpuid.CPU.X64Level())
 	if cpuid.CPU.AVX10Level > 0 {
 		fmt.Println("AVX10 level:", cpuid.CPU.AVX10Level)
@@ -73,6 +130,28 @@ func main() {
 	fmt.Println("L1 Data Cache:", cpuid.CPU.Cache.L1D, "bytes")
 	fmt.Println("L2 Cache:", cpuid.CPU.Cache.L2, "bytes")
 	fmt.Println("L3 Cache:", cpuid.CPU.Cache.L3, "bytes")
+	if len(cpuid.CPU.Caches) > 0 {
+		fmt.Println("Caches:")
+	}
+	for _, ci := range cpuid.CPU.Caches {
+		fmt.Printf("  L%d %s: %d bytes, %d-way, %d sets, %d byte lines, shared by %d", ci.Level, ci.Type, ci.Size, ci.Ways, ci.Sets, ci.LineSize, ci.SharedBy)
+		if ci.Inclusive {
+			fmt.Print(", inclusive")
+		}
+		fmt.Println()
+	}
+	if len(cpuid.CPU.TLBs) > 0 {
+		fmt.Println("TLBs:")
+	}
+	for _, t := range cpuid.CPU.TLBs {
+		fmt.Printf("  L%d %s: %s pages, %d entries, %d-way\n", t.Level, t.Type, t.PageSizes, t.Entries, t.Ways)
+	}
+	if topo := cpuid.CPU.Topology; len(topo.Levels) > 0 {
+		fmt.Printf("Topology: %d logical CPUs, %d packages, x2APIC ID %d\n", topo.LogicalCPUs, topo.Packages, topo.X2APICID)
+		for _, l := range topo.Levels {
+			fmt.Printf("  %s: %d logical CPUs, shift %d, %d per package\n", l.Type, l.LogicalCPUs, l.Shift, topo.PerPackage(l.Type))
+		}
+	}
 	if cpuid.CPU.Hz > 0 {
 		fmt.Println("Frequency:", cpuid.CPU.Hz, "Hz")
 	}
@@ -91,3 +170,25 @@ func main() {
 			"General Purpose Counters:", cpuid.CPU.PMU.NumGPCounters)
 	}
 }
+
+// dump will write the CPUID leaves to the file, or stdout if empty.
+func dump(file string) {
+	if file == "" {
+		if err := cpuid.CPU.Dump(os.Stdout); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	err = cpuid.CPU.Dump(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+	log.Println("CPUID leaves written to", file)
+}
@@ -0,0 +1,329 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+// featureCodes contains the stable wire code of each feature.
+// FeatureID values may change between releases, but these codes must not.
+// Never change or reuse a code. New features must be given the next unused code.
+// Code 0 is reserved for unknown features.
+var featureCodes = [lastID]uint16{
+	ADX:                            1,
+	AESNI:                          2,
+	AMD3DNOW:                       3,
+	AMD3DNOWEXT:                    4,
+	AMXBF16:                        5,
+	AMXFP16:                        6,
+	AMXINT8:                        7,
+	AMXFP8:                         8,
+	AMXTILE:                        9,
+	AMXTF32:                        10,
+	AMXCOMPLEX:                     11,
+	AMXTRANSPOSE:                   12,
+	APX_F:                          13,
+	AVX:                            14,
+	AVX10:                          15,
+	AVX10_128:                      16,
+	AVX10_256:                      17,
+	AVX10_512:                      18,
+	AVX2:                           19,
+	AVX512BF16:                     20,
+	AVX512BITALG:                   21,
+	AVX512BMM:                      22,
+	AVX512BW:                       23,
+	AVX512CD:                       24,
+	AVX512DQ:                       25,
+	AVX512ER:                       26,
+	AVX512F:                        27,
+	AVX512FP16:                     28,
+	AVX512IFMA:                     29,
+	AVX512PF:                       30,
+	AVX512VBMI:                     31,
+	AVX512VBMI2:                    32,
+	AVX512VL:                       33,
+	AVX512VNNI:                     34,
+	AVX512VP2INTERSECT:             35,
+	AVX512VPOPCNTDQ:                36,
+	AVXIFMA:                        37,
+	AVXNECONVERT:                   38,
+	AVXSLOW:                        39,
+	AVXVNNI:                        40,
+	AVXVNNIINT8:                    41,
+	AVXVNNIINT16:                   42,
+	BHI_CTRL:                       43,
+	BMI1:                           44,
+	BMI2:                           45,
+	CETIBT:                         46,
+	CETSS:                          47,
+	CLDEMOTE:                       48,
+	CLMUL:                          49,
+	CLZERO:                         50,
+	CMOV:                           51,
+	CMPCCXADD:                      52,
+	CMPSB_SCADBS_SHORT:             53,
+	CMPXCHG8:                       54,
+	CPBOOST:                        55,
+	CPPC:                           56,
+	CX16:                           57,
+	EFER_LMSLE_UNS:                 58,
+	ENQCMD:                         59,
+	ERMS:                           60,
+	F16C:                           61,
+	FLUSH_L1D:                      62,
+	FMA3:                           63,
+	FMA4:                           64,
+	FP128:                          65,
+	FP256:                          66,
+	FSRM:                           67,
+	FXSR:                           68,
+	FXSROPT:                        69,
+	GFNI:                           70,
+	HLE:                            71,
+	HRESET:                         72,
+	HTT:                            73,
+	HWA:                            74,
+	HYBRID_CPU:                     75,
+	HYPERVISOR:                     76,
+	IA32_ARCH_CAP:                  77,
+	IA32_CORE_CAP:                  78,
+	IBPB:                           79,
+	IBPB_BRTYPE:                    80,
+	IBRS:                           81,
+	IBRS_PREFERRED:                 82,
+	IBRS_PROVIDES_SMP:              83,
+	IBS:                            84,
+	IBSBRNTRGT:                     85,
+	IBSFETCHSAM:                    86,
+	IBSFFV:                         87,
+	IBSOPCNT:                       88,
+	IBSOPCNTEXT:                    89,
+	IBSOPSAM:                       90,
+	IBSRDWROPCNT:                   91,
+	IBSRIPINVALIDCHK:               92,
+	IBS_FETCH_CTLX:                 93,
+	IBS_OPDATA4:                    94,
+	IBS_OPFUSE:                     95,
+	IBS_PREVENTHOST:                96,
+	IBS_ZEN4:                       97,
+	IDPRED_CTRL:                    98,
+	INT_WBINVD:                     99,
+	INVLPGB:                        100,
+	KEYLOCKER:                      101,
+	KEYLOCKERW:                     102,
+	LAHF:                           103,
+	LAM:                            104,
+	LBRVIRT:                        105,
+	LZCNT:                          106,
+	MCAOVERFLOW:                    107,
+	MCDT_NO:                        108,
+	MCOMMIT:                        109,
+	MD_CLEAR:                       110,
+	MMX:                            111,
+	MMXEXT:                         112,
+	MOVBE:                          113,
+	MOVDIR64B:                      114,
+	MOVDIRI:                        115,
+	MOVSB_ZL:                       116,
+	MOVU:                           117,
+	MPX:                            118,
+	MSRIRC:                         119,
+	MSRLIST:                        120,
+	MSR_PAGEFLUSH:                  121,
+	NRIPS:                          122,
+	NX:                             123,
+	OSXSAVE:                        124,
+	PCONFIG:                        125,
+	POPCNT:                         126,
+	PPIN:                           127,
+	PREFETCHI:                      128,
+	PSFD:                           129,
+	RDPRU:                          130,
+	RDRAND:                         131,
+	RDSEED:                         132,
+	RDTSCP:                         133,
+	RRSBA_CTRL:                     134,
+	RTM:                            135,
+	RTM_ALWAYS_ABORT:               136,
+	SBPB:                           137,
+	SERIALIZE:                      138,
+	SEV:                            139,
+	SEV_64BIT:                      140,
+	SEV_ALTERNATIVE:                141,
+	SEV_DEBUGSWAP:                  142,
+	SEV_ES:                         143,
+	SEV_RESTRICTED:                 144,
+	SEV_SNP:                        145,
+	SGX:                            146,
+	SGXLC:                          147,
+	SGXPQC:                         148,
+	SHA:                            149,
+	SME:                            150,
+	SME_COHERENT:                   151,
+	SM3_X86:                        152,
+	SM4_X86:                        153,
+	SPEC_CTRL_SSBD:                 154,
+	SRBDS_CTRL:                     155,
+	SRSO_MSR_FIX:                   156,
+	SRSO_NO:                        157,
+	SRSO_USER_KERNEL_NO:            158,
+	SSE:                            159,
+	SSE2:                           160,
+	SSE3:                           161,
+	SSE4:                           162,
+	SSE42:                          163,
+	SSE4A:                          164,
+	SSSE3:                          165,
+	STIBP:                          166,
+	STIBP_ALWAYSON:                 167,
+	STOSB_SHORT:                    168,
+	SUCCOR:                         169,
+	SVM:                            170,
+	SVMDA:                          171,
+	SVMFBASID:                      172,
+	SVML:                           173,
+	SVMNP:                          174,
+	SVMPF:                          175,
+	SVMPFT:                         176,
+	SYSCALL:                        177,
+	SYSEE:                          178,
+	TBM:                            179,
+	TDX_GUEST:                      180,
+	TLB_FLUSH_NESTED:               181,
+	TME:                            182,
+	TOPEXT:                         183,
+	TSA_L1_NO:                      184,
+	TSA_SQ_NO:                      185,
+	TSA_VERW_CLEAR:                 186,
+	TSCRATEMSR:                     187,
+	TSXLDTRK:                       188,
+	VAES:                           189,
+	VMCBCLEAN:                      190,
+	VMPL:                           191,
+	VMSA_REGPROT:                   192,
+	VMX:                            193,
+	VPCLMULQDQ:                     194,
+	VTE:                            195,
+	WAITPKG:                        196,
+	WBNOINVD:                       197,
+	WRMSRNS:                        198,
+	X87:                            199,
+	XGETBV1:                        200,
+	XOP:                            201,
+	XSAVE:                          202,
+	XSAVEC:                         203,
+	XSAVEOPT:                       204,
+	XSAVES:                         205,
+	AESARM:                         206,
+	ARMCPUID:                       207,
+	ASIMD:                          208,
+	ASIMDDP:                        209,
+	ASIMDHP:                        210,
+	ASIMDRDM:                       211,
+	ATOMICS:                        212,
+	CRC32:                          213,
+	DCPOP:                          214,
+	EVTSTRM:                        215,
+	FCMA:                           216,
+	FHM:                            217,
+	FP:                             218,
+	FPHP:                           219,
+	GPA:                            220,
+	JSCVT:                          221,
+	LRCPC:                          222,
+	PMULL:                          223,
+	RNDR:                           224,
+	TLB:                            225,
+	TS:                             226,
+	SHA1:                           227,
+	SHA2:                           228,
+	SHA3:                           229,
+	SHA512:                         230,
+	SM3:                            231,
+	SM4:                            232,
+	SVE:                            233,
+	PMU_FIXEDCOUNTER_CYCLES:        234,
+	PMU_FIXEDCOUNTER_REFCYCLES:     235,
+	PMU_FIXEDCOUNTER_INSTRUCTIONS:  236,
+	PMU_FIXEDCOUNTER_TOPDOWN_SLOTS: 237,
+}
+
+// vendorCodes contains the stable wire code of each vendor.
+// Never change or reuse a code. New vendors must be given the next unused code.
+var vendorCodes = [lastVendor]uint8{
+	VendorUnknown: 0,
+	Intel:         1,
+	AMD:           2,
+	VIA:           3,
+	Transmeta:     4,
+	NSC:           5,
+	KVM:           6,
+	MSVM:          7,
+	VMware:        8,
+	XenHVM:        9,
+	Bhyve:         10,
+	Hygon:         11,
+	SiS:           12,
+	RDC:           13,
+	Ampere:        14,
+	ARM:           15,
+	Broadcom:      16,
+	Cavium:        17,
+	DEC:           18,
+	Fujitsu:       19,
+	Infineon:      20,
+	Motorola:      21,
+	NVIDIA:        22,
+	AMCC:          23,
+	Qualcomm:      24,
+	Marvell:       25,
+	QEMU:          26,
+	QNX:           27,
+	ACRN:          28,
+	SRE:           29,
+	Apple:         30,
+	HiSilicon:     31,
+	Microsoft:     32,
+	Phytium:       33,
+	Zhaoxin:       34,
+}
+
+// Code returns the stable wire code of the feature.
+// Unlike the FeatureID value, the code will not change between releases.
+// 0 is returned for unknown features.
+func (i FeatureID) Code() uint16 {
+	if i < firstID || i >= lastID {
+		return 0
+	}
+	return featureCodes[i]
+}
+
+// FeatureFromCode returns the feature with the wire code.
+// UNKNOWN is returned if the code is not known.
+func FeatureFromCode(code uint16) FeatureID {
+	if code == 0 {
+		return UNKNOWN
+	}
+	for i, c := range featureCodes {
+		if c == code {
+			return FeatureID(i)
+		}
+	}
+	return UNKNOWN
+}
+
+// code returns the stable wire code of the vendor.
+func (i Vendor) code() uint8 {
+	if i < VendorUnknown || i >= lastVendor {
+		return 0
+	}
+	return vendorCodes[i]
+}
+
+// vendorFromCode returns the vendor with the wire code.
+func vendorFromCode(code uint8) Vendor {
+	for i, c := range vendorCodes {
+		if c == code {
+			return Vendor(i)
+		}
+	}
+	return VendorUnknown
+}
@@ -11,13 +11,12 @@
 package cpuid
 
 import (
+	"errors"
 	"flag"
-	"fmt"
-	"math"
 	"math/bits"
-	"os"
 	"runtime"
 	"strings"
+	"sync/atomic"
 )
 
 // AMD refererence: https://www.amd.com/system/files/TechDocs/25481.pdf
@@ -60,11 +59,16 @@ const (
 	ACRN
 	SRE
 	Apple
+	HiSilicon
+	Microsoft
+	Phytium
+	Zhaoxin
 
 	lastVendor
 )
 
 //go:generate stringer -type=FeatureID,Vendor
+//go:generate go run gen_featureinfo.go
 
 // FeatureID is the ID of a specific cpu feature.
 type FeatureID int
@@ -136,7 +140,7 @@ const (
 	ERMS                                 // Enhanced REP MOVSB/STOSB
 	F16C                                 // Half-precision floating-point conversion
 	FLUSH_L1D                            // Flush L1D cache
-	FMA3                                 // Intel FMA 3. Does not imply AVX.
+	FMA3                                 // Intel FMA 3. Requires AVX.
 	FMA4                                 // Bulldozer FMA4 functions
 	FP128                                // AMD: When set, the internal FP/SIMD execution datapath is no more than 128-bits wide
 	FP256                                // AMD: When set, the internal FP/SIMD execution datapath is no more than 256-bits wide
@@ -311,10 +315,10 @@ const (
 	SVE      // Scalable Vector Extension
 
 	// PMU
-	PMU_FIXEDCOUNTER_CYCLES
-	PMU_FIXEDCOUNTER_REFCYCLES
-	PMU_FIXEDCOUNTER_INSTRUCTIONS
-	PMU_FIXEDCOUNTER_TOPDOWN_SLOTS
+	PMU_FIXEDCOUNTER_CYCLES        // Fixed function counter for core cycles
+	PMU_FIXEDCOUNTER_REFCYCLES     // Fixed function counter for reference cycles
+	PMU_FIXEDCOUNTER_INSTRUCTIONS  // Fixed function counter for instructions retired
+	PMU_FIXEDCOUNTER_TOPDOWN_SLOTS // Fixed function counter for topdown slots
 
 	// Keep it last. It automatically defines the size of []flagSet
 	lastID
@@ -333,9 +337,11 @@ type CPUInfo struct {
 	PhysicalCores          int     // Number of physical processor cores in your CPU. Will be 0 if undetectable.
 	ThreadsPerCore         int     // Number of threads per physical core. Will be 1 if undetectable.
 	LogicalCores           int     // Number of physical cores times threads that can run on each core through the use of hyperthreading. Will be 0 if undetectable.
-	Family                 int     // CPU family number
-	Model                  int     // CPU model number
+	Family                 int     // CPU family number. On ARM the MIDR architecture.
+	Model                  int     // CPU model number. On ARM the MIDR part number.
 	Stepping               int     // CPU stepping info
+	Variant                int     // ARM MIDR variant, the major revision of the part
+	Revision               int     // ARM MIDR revision, the minor revision of the part
 	CacheLine              int     // Cache line size in bytes. Will be 0 if undetectable.
 	Hz                     int64   // Clock speed, if known, 0 otherwise. Will attempt to contain base clock speed.
 	BoostFreq              int64   // Max clock speed, if known, 0 otherwise
@@ -345,13 +351,22 @@ type CPUInfo struct {
 		L2  int // L2 Cache (per core or shared). Will be -1 if undetected
 		L3  int // L3 Cache (per core, per ccx or shared). Will be -1 if undetected
 	}
+	Caches           []CacheInfo // Caches in the order reported by the CPU, or by the OS if not reported by the CPU. Nil if undetected
+	TLBs             []TLBInfo   // TLBs in the order reported by the CPU. Nil if undetected
+	Topology         Topology
 	SGX              SGXSupport
 	AMDMemEncryption AMDMemEncryptionSupport
 	AVX10Level       uint8
 	PMU              PerformanceMonitoringInfo //  holds information about the PMU
+	// Partial is set when detection was limited to the features known by golang.org/x/sys/cpu,
+	// which is the case for builds with the noasm, appengine or gccgo tags,
+	// and on architectures other than amd64, 386 and arm64.
+	Partial bool
 
 	maxFunc   uint32
 	maxExFunc uint32
+	src       Source
+	leaves    *leafCache
 }
 
 // PerformanceMonitoringInfo holds information about CPU performance monitoring capabilities.
@@ -384,6 +399,35 @@ var xgetbv func(index uint32) (eax, edx uint32)
 var rdtscpAsm func() (eax, ebx, ecx, edx uint32)
 var darwinHasAVX512 = func() bool { return false }
 
+// Source provides raw CPUID and XGETBV results.
+// It can be used with DetectFrom to decode CPU information
+// from other sources than the CPU the program is running on.
+//
+// A Source must return the same values when called repeatedly with the same arguments.
+type Source interface {
+	// CPUID returns the registers of CPUID with EAX=op and ECX=0.
+	CPUID(op uint32) (eax, ebx, ecx, edx uint32)
+	// CPUIDEX returns the registers of CPUID with EAX=op and ECX=op2.
+	CPUIDEX(op, op2 uint32) (eax, ebx, ecx, edx uint32)
+	// XGETBV returns the extended control register selected by index.
+	XGETBV(index uint32) (eax, edx uint32)
+}
+
+// nativeSource reads from the CPU the program is running on.
+type nativeSource struct{}
+
+func (nativeSource) CPUID(op uint32) (eax, ebx, ecx, edx uint32) {
+	return cpuid(op)
+}
+
+func (nativeSource) CPUIDEX(op, op2 uint32) (eax, ebx, ecx, edx uint32) {
+	return cpuidex(op, op2)
+}
+
+func (nativeSource) XGETBV(index uint32) (eax, edx uint32) {
+	return xgetbv(index)
+}
+
 // CPU contains information about the CPU as detected on startup,
 // or when Detect last was called.
 //
@@ -396,62 +440,165 @@ func init() {
 }
 
 // Detect will re-detect current CPU info.
-// This will replace the content of the exported CPU variable.
+// This will replace the content of the exported CPU variable
+// and publish a new snapshot returned by Current.
 //
 // Unless you expect the CPU to change while you are running your program
 // you should not need to call this function.
 // If you call this, you must ensure that no other goroutine is accessing the
-// exported CPU variable.
+// exported CPU variable. Use Redetect to avoid this.
 func Detect() {
-	// Set defaults
-	CPU.ThreadsPerCore = 1
-	CPU.Cache.L1I = -1
-	CPU.Cache.L1D = -1
-	CPU.Cache.L2 = -1
-	CPU.Cache.L3 = -1
-	safe := true
-	if detectArmFlag != nil {
-		safe = !*detectArmFlag
-	}
-	addInfo(&CPU, safe)
-	if displayFeats != nil && *displayFeats {
-		fmt.Println("cpu features:", strings.Join(CPU.FeatureSet(), ","))
-		// Exit with non-zero so tests will print value.
-		os.Exit(1)
-	}
-	if disableFlag != nil {
-		s := strings.Split(*disableFlag, ",")
-		for _, feat := range s {
-			feat := ParseFeature(strings.TrimSpace(feat))
-			if feat != UNKNOWN {
-				CPU.featureSet.unset(feat)
-			}
+	CPU = detect()
+	publish(CPU)
+}
+
+// current contains the latest published snapshot.
+var current atomic.Pointer[CPUInfo]
+
+// armUnsafe is set when DetectARM has been called.
+var armUnsafe atomic.Bool
+
+// Current returns a copy of the current CPU information snapshot.
+// Snapshots are replaced by Detect, Redetect, WithDisabled and Update,
+// but a published snapshot is never changed, so it is safe for concurrent use.
+// Changing the returned value does not change the snapshot.
+//
+// Changes made directly to the exported CPU variable are not reflected.
+func Current() *CPUInfo {
+	c := *current.Load()
+	return &c
+}
+
+// Redetect will detect the current CPU and publish it as a new snapshot.
+// The exported CPU variable is not modified, so this can be called
+// while other goroutines are running, for example after a VM live migration.
+// Features disabled with WithDisabled are not retained.
+func Redetect() *CPUInfo {
+	c := detect()
+	publish(c)
+	return &c
+}
+
+// WithDisabled will publish a new snapshot with the features disabled
+// and return a copy of it.
+// The exported CPU variable is not modified.
+func WithDisabled(ids ...FeatureID) *CPUInfo {
+	return Update(func(c *CPUInfo) {
+		c.Disable(ids...)
+	})
+}
+
+// Update will publish a new snapshot made by calling fn with a copy of the current snapshot,
+// and return a copy of it.
+// fn may be called more than once if other goroutines publish snapshots concurrently.
+// The exported CPU variable is not modified.
+func Update(fn func(c *CPUInfo)) *CPUInfo {
+	for {
+		old := current.Load()
+		c := *old
+		fn(&c)
+		if current.CompareAndSwap(old, &c) {
+			res := c
+			return &res
 		}
 	}
 }
 
+// publish will store a copy of c as the current snapshot.
+func publish(c CPUInfo) {
+	current.Store(&c)
+}
+
+// detect will detect the current CPU and apply flags.
+func detect() CPUInfo {
+	var c CPUInfo
+	// Set defaults
+	c.ThreadsPerCore = 1
+	c.Cache.L1I = -1
+	c.Cache.L1D = -1
+	c.Cache.L2 = -1
+	c.Cache.L3 = -1
+	safe := !armUnsafe.Load()
+	if cpuFlags.arm {
+		safe = false
+	}
+	addInfo(&c, safe)
+	if len(c.Caches) == 0 {
+		c.setCaches(osCachesOnce())
+	}
+	c.Topology.setSystem(osTopologyOnce())
+	// Masks are applied before c is published, so published snapshots are never modified.
+	applyGODEBUG(&c)
+	applyEnv(&c)
+	applyFlags(&c)
+	applyDispatchOverrides()
+	return c
+}
+
+// DetectFrom will decode x86 CPU information from the supplied source.
+// The returned CPUInfo is independent of the exported CPU variable,
+// and flags or other global settings are not applied to it.
+// This can be used on all platforms.
+func DetectFrom(src Source) (CPUInfo, error) {
+	var c CPUInfo
+	if src == nil {
+		return c, errors.New("cpuid: nil source")
+	}
+	c.ThreadsPerCore = 1
+	addInfoFrom(&c, src)
+	if c.maxFunc == 0 && c.VendorString == "" {
+		return c, errors.New("cpuid: source returned no CPUID data")
+	}
+	return c, nil
+}
+
+// addInfoFrom will decode x86 CPUID information from src into c.
+func addInfoFrom(c *CPUInfo, src Source) {
+	c.src = src
+	c.maxFunc = maxFunctionID(src)
+	c.maxExFunc = maxExtendedFunction(src)
+	c.leaves = &leafCache{src: src, maxFunc: c.maxFunc, maxExFunc: c.maxExFunc}
+	c.VendorID, c.VendorString = vendorID(src)
+	c.HypervisorVendorID, c.HypervisorVendorString = hypervisorVendorID(src)
+	c.BrandName = c.brandName()
+	c.CacheLine = c.cacheLine()
+	c.Family, c.Model, c.Stepping = c.familyModel()
+	c.ThreadsPerCore = c.threadsPerCore()
+	c.featureSet = support(c)
+	c.SGX = c.hasSGX(c.featureSet.inSet(SGX), c.featureSet.inSet(SGXLC))
+	c.AMDMemEncryption = c.hasAMDMemEncryption(c.featureSet.inSet(SME) || c.featureSet.inSet(SEV))
+	c.LogicalCores = c.logicalCores()
+	c.PhysicalCores = c.physicalCores()
+	c.AVX10Level = c.supportAVX10()
+	c.cacheSize()
+	c.tlbs()
+	c.Topology = c.topology()
+	c.frequencies()
+	if c.maxFunc >= 0x0A {
+		eax, ebx, _, edx := src.CPUID(0x0A)
+		c.PMU = parseLeaf0AH(c, eax, ebx, edx)
+	}
+}
+
 // DetectARM will detect ARM64 features.
 // This is NOT done automatically since it can potentially crash
 // if the OS does not handle the command.
 // If in the future this can be done safely this function may not
 // do anything.
 func DetectARM() {
-	addInfo(&CPU, false)
+	armUnsafe.Store(true)
+	Detect()
 }
 
-var detectArmFlag *bool
-var displayFeats *bool
-var disableFlag *string
-
-// Flags will enable flags.
+// Flags will enable flags on the default command line flag set.
 // This must be called *before* flag.Parse AND
 // Detect must be called after the flags have been parsed.
 // Note that this means that any detection used in init() functions
-// will not contain these flags.
+// will not contain these flags. Use the environment variables
+// EnvDisable, EnvEnable and EnvMaxLevel for that.
+// See FlagsWith for the flags registered.
 func Flags() {
-	disableFlag = flag.String("cpu.disable", "", "disable cpu features; comma separated list")
-	displayFeats = flag.Bool("cpu.features", false, "lists cpu features and exits")
-	detectArmFlag = flag.Bool("cpu.arm", false, "allow ARM features to be detected; can potentially crash")
+	FlagsOn(flag.CommandLine)
 }
 
 // Supports returns whether the CPU supports all of the requested features.
@@ -526,17 +673,100 @@ func (c CPUInfo) X64Level() int {
 	return 0
 }
 
+// levelFeatures returns the features of x86-64 microarchitecture level 1 to 4.
+func levelFeatures(level int) Features {
+	switch level {
+	case 1:
+		return level1Features
+	case 2:
+		return level2Features
+	case 3:
+		return level3Features
+	case 4:
+		return level4Features
+	}
+	return nil
+}
+
+// LimitToLevel clears all features not in x86-64 microarchitecture level 1 to 4.
+// Prerequisites of the level features, as well as SYSCALL and SYSEE, are kept,
+// so X64Level will return the level, unless the CPU has a lower level.
+// Nothing is changed and false is returned if the level is invalid
+// or the CPU has no microarchitecture level.
+// Only c is changed. Use Update to limit the snapshot returned by Current.
+func (c *CPUInfo) LimitToLevel(level int) bool {
+	keep := levelFeatures(level)
+	if keep == nil || c.X64Level() == 0 {
+		return false
+	}
+	c.LimitTo(FeatureSet{s: *keep})
+	return true
+}
+
+// LimitTo clears all features not in profile.
+// Prerequisites of the features in profile are kept as well,
+// so a profile with AVX2 will keep AVX, OSXSAVE and XSAVE.
+// SYSCALL and SYSEE are always kept, since X64Level requires one of them.
+// Fields derived from features, like AVX10Level, are updated.
+// Only c is changed. Use Update to limit the snapshot returned by Current.
+func (c *CPUInfo) LimitTo(profile FeatureSet) {
+	keep := profile.s
+	keep.or(*oneOfLevel)
+	profile.each(func(id FeatureID) bool {
+		keep.or(featureRequires[id])
+		return true
+	})
+	for i := range c.featureSet {
+		c.featureSet[i] &= keep[i]
+	}
+	c.updateDerived()
+}
+
+// updateDerived updates fields derived from features after features have been removed.
+func (c *CPUInfo) updateDerived() {
+	if !c.featureSet.inSet(AVX10) {
+		c.AVX10Level = 0
+	}
+	if !c.featureSet.inSet(SGX) {
+		c.SGX.Available = false
+	}
+	if !c.featureSet.inSet(SGXLC) {
+		c.SGX.LaunchControl = false
+	}
+	if !c.featureSet.inSet(SME) && !c.featureSet.inSet(SEV) {
+		c.AMDMemEncryption.Available = false
+	}
+}
+
 // Disable will disable one or several features.
+// Features that depend on a disabled feature are also disabled,
+// so disabling AVX will also disable AVX2, FMA3, AVX512F, etc.
+// Fields derived from features, like AVX10Level, are updated.
+// Only c is changed, so CPU.Disable does not affect Current or Dispatch.
+// Use WithDisabled for that.
 func (c *CPUInfo) Disable(ids ...FeatureID) bool {
 	for _, id := range ids {
+		if id <= firstID || id >= lastID {
+			continue
+		}
 		c.featureSet.unset(id)
+		for i, v := range featureDependents[id] {
+			c.featureSet[i] &^= v
+		}
 	}
+	c.updateDerived()
 	return true
 }
 
-// Enable will disable one or several features even if they were undetected.
+// Enable will enable one or several features even if they were undetected.
 // This is of course not recommended for obvious reasons.
+// If any prerequisites of the features are missing, no features are enabled and false is returned.
+// Use MissingPrerequisites to see which features are missing.
+// Only c is changed, and published snapshots are not affected.
 func (c *CPUInfo) Enable(ids ...FeatureID) bool {
+	if c.MissingPrerequisites(ids...).Len() > 0 {
+		return false
+	}
 	for _, id := range ids {
 		c.featureSet.set(id)
 	}
@@ -592,10 +822,10 @@ func (c CPUInfo) SveLengths() (vl, pl uint64) {
 // to another CPU.
 // If the current core cannot be detected, -1 will be returned.
 func (c CPUInfo) LogicalCPU() int {
-	if c.maxFunc < 1 {
+	if c.maxFunc < 1 || c.src == nil {
 		return -1
 	}
-	_, ebx, _, _ := cpuid(1)
+	_, ebx, _, _ := c.src.CPUID(1)
 	return int(ebx >> 24)
 }
 
@@ -603,15 +833,15 @@ func (c CPUInfo) LogicalCPU() int {
 // supported, use it, otherwise parse the brand string. Yes, really.
 func (c *CPUInfo) frequencies() {
 	c.Hz, c.BoostFreq = 0, 0
-	mfi := maxFunctionID()
+	mfi := c.maxFunc
 	if mfi >= 0x15 {
-		eax, ebx, ecx, _ := cpuid(0x15)
+		eax, ebx, ecx, _ := c.src.CPUID(0x15)
 		if eax != 0 && ebx != 0 && ecx != 0 {
 			c.Hz = (int64(ecx) * int64(ebx)) / int64(eax)
 		}
 	}
 	if mfi >= 0x16 {
-		a, b, _, _ := cpuid(0x16)
+		a, b, _, _ := c.src.CPUID(0x16)
 		// Base...
 		if a&0xffff > 0 {
 			c.Hz = int64(a&0xffff) * 1_000_000
@@ -679,7 +909,7 @@ func (c *CPUInfo) frequencies() {
 // VM Will return true if the cpu id indicates we are in
 // a virtual machine.
 func (c CPUInfo) VM() bool {
-	return CPU.featureSet.inSet(HYPERVISOR)
+	return c.featureSet.inSet(HYPERVISOR)
 }
 
 // flags contains detected cpu features and characteristics
@@ -794,21 +1024,21 @@ func (s flagSet) Strings() []string {
 	return r
 }
 
-func maxExtendedFunction() uint32 {
-	eax, _, _, _ := cpuid(0x80000000)
+func maxExtendedFunction(src Source) uint32 {
+	eax, _, _, _ := src.CPUID(0x80000000)
 	return eax
 }
 
-func maxFunctionID() uint32 {
-	a, _, _, _ := cpuid(0)
+func maxFunctionID(src Source) uint32 {
+	a, _, _, _ := src.CPUID(0)
 	return a
 }
 
-func brandName() string {
-	if maxExtendedFunction() >= 0x80000004 {
+func (c *CPUInfo) brandName() string {
+	if c.maxExFunc >= 0x80000004 {
 		v := make([]uint32, 0, 48)
 		for i := uint32(0); i < 3; i++ {
-			a, b, c, d := cpuid(0x80000002 + i)
+			a, b, c, d := c.src.CPUID(0x80000002 + i)
 			v = append(v, a, b, c, d)
 		}
 		return strings.Trim(string(valAsString(v...)), " ")
@@ -816,9 +1046,9 @@ func brandName() string {
 	return "unknown"
 }
 
-func threadsPerCore() int {
-	mfi := maxFunctionID()
-	vend, _ := vendorID()
+func (c *CPUInfo) threadsPerCore() int {
+	mfi := c.maxFunc
+	vend := c.VendorID
 
 	if mfi < 0x4 || (vend != Intel && vend != AMD) {
 		return 1
@@ -828,12 +1058,12 @@ func threadsPerCore() int {
 		if vend != Intel {
 			return 1
 		}
-		_, b, _, d := cpuid(1)
+		_, b, _, d := c.src.CPUID(1)
 		if (d & (1 << 28)) != 0 {
 			// v will contain logical core count
 			v := (b >> 16) & 255
 			if v > 1 {
-				a4, _, _, _ := cpuid(4)
+				a4, _, _, _ := c.src.CPUID(4)
 				// physical cores
 				v2 := (a4 >> 26) + 1
 				if v2 > 0 {
@@ -843,17 +1073,16 @@ func threadsPerCore() int {
 		}
 		return 1
 	}
-	_, b, _, _ := cpuidex(0xb, 0)
+	_, b, _, _ := c.src.CPUIDEX(0xb, 0)
 	if b&0xffff == 0 {
 		if vend == AMD {
 			// if >= Zen 2 0x8000001e EBX 15-8 bits means threads per core.
 			// The number of threads per core is ThreadsPerCore+1
 			// See PPR for AMD Family 17h Models 00h-0Fh (page 82)
-			fam, _, _ := familyModel()
-			_, _, _, d := cpuid(1)
-			if (d&(1<<28)) != 0 && fam >= 23 {
-				if maxExtendedFunction() >= 0x8000001e {
-					_, b, _, _ := cpuid(0x8000001e)
+			_, _, _, d := c.src.CPUID(1)
+			if (d&(1<<28)) != 0 && c.Family >= 23 {
+				if c.maxExFunc >= 0x8000001e {
+					_, b, _, _ := c.src.CPUID(0x8000001e)
 					return int((b>>8)&0xff) + 1
 				}
 				return 2
@@ -864,10 +1093,9 @@ func threadsPerCore() int {
 	return int(b & 0xffff)
 }
 
-func logicalCores() int {
-	mfi := maxFunctionID()
-	v, _ := vendorID()
-	switch v {
+func (c *CPUInfo) logicalCores() int {
+	mfi := c.maxFunc
+	switch c.VendorID {
 	case Intel:
 		// Use this on old Intel processors
 		if mfi < 0xb {
@@ -877,25 +1105,25 @@ func logicalCores() int {
 			// CPUID.1:EBX[23:16] represents the maximum number of addressable IDs (initial APIC ID)
 			// that can be assigned to logical processors in a physical package.
 			// The value may not be the same as the number of logical processors that are present in the hardware of a physical package.
-			_, ebx, _, _ := cpuid(1)
+			_, ebx, _, _ := c.src.CPUID(1)
 			logical := (ebx >> 16) & 0xff
 			return int(logical)
 		}
-		_, b, _, _ := cpuidex(0xb, 1)
+		_, b, _, _ := c.src.CPUIDEX(0xb, 1)
 		return int(b & 0xffff)
 	case AMD, Hygon:
-		_, b, _, _ := cpuid(1)
+		_, b, _, _ := c.src.CPUID(1)
 		return int((b >> 16) & 0xff)
 	default:
 		return 0
 	}
 }
 
-func familyModel() (family, model, stepping int) {
-	if maxFunctionID() < 0x1 {
+func (c *CPUInfo) familyModel() (family, model, stepping int) {
+	if c.maxFunc < 0x1 {
 		return 0, 0, 0
 	}
-	eax, _, _, _ := cpuid(1)
+	eax, _, _, _ := c.src.CPUID(1)
 	// If BaseFamily[3:0] is less than Fh then ExtendedFamily[7:0] is reserved and Family is equal to BaseFamily[3:0].
 	family = int((eax >> 8) & 0xf)
 	extFam := family == 0x6 // Intel is 0x6, needs extended model.
@@ -914,28 +1142,27 @@ func familyModel() (family, model, stepping int) {
 	return family, model, stepping
 }
 
-func physicalCores() int {
-	v, _ := vendorID()
-	switch v {
+func (c *CPUInfo) physicalCores() int {
+	switch c.VendorID {
 	case Intel:
-		lc := logicalCores()
-		tpc := threadsPerCore()
+		lc := c.LogicalCores
+		tpc := c.ThreadsPerCore
 		if lc > 0 && tpc > 0 {
 			return lc / tpc
 		}
 		return 0
 	case AMD, Hygon:
-		lc := logicalCores()
-		tpc := threadsPerCore()
+		lc := c.LogicalCores
+		tpc := c.ThreadsPerCore
 		if lc > 0 && tpc > 0 {
 			return lc / tpc
 		}
 
 		// The following is inaccurate on AMD EPYC 7742 64-Core Processor
-		if maxExtendedFunction() >= 0x80000008 {
-			_, _, c, _ := cpuid(0x80000008)
-			if c&0xff > 0 {
-				return int(c&0xff) + 1
+		if c.maxExFunc >= 0x80000008 {
+			_, _, ecx, _ := c.src.CPUID(0x80000008)
+			if ecx&0xff > 0 {
+				return int(ecx&0xff) + 1
 			}
 		}
 	}
@@ -952,6 +1179,7 @@ var vendorMapping = map[string]Vendor{
 	"GenuineTMx86": Transmeta,
 	"Geode by NSC": NSC,
 	"VIA VIA VIA ": VIA,
+	"  Shanghai  ": Zhaoxin,
 	"KVMKVMKVM":    KVM,
 	"Linux KVM Hv": KVM,
 	"TCGTCGTCGTCG": QEMU,
@@ -970,8 +1198,8 @@ var vendorMapping = map[string]Vendor{
 	"Apple VZ":     Apple,
 }
 
-func vendorID() (Vendor, string) {
-	_, b, c, d := cpuid(0)
+func vendorID(src Source) (Vendor, string) {
+	_, b, c, d := src.CPUID(0)
 	v := string(valAsString(b, d, c))
 	vend, ok := vendorMapping[v]
 	if !ok {
@@ -980,9 +1208,9 @@ func vendorID() (Vendor, string) {
 	return vend, v
 }
 
-func hypervisorVendorID() (Vendor, string) {
+func hypervisorVendorID(src Source) (Vendor, string) {
 	// https://lwn.net/Articles/301888/
-	_, b, c, d := cpuid(0x40000000)
+	_, b, c, d := src.CPUID(0x40000000)
 	v := string(valAsString(b, c, d))
 	vend, ok := vendorMapping[v]
 	if !ok {
@@ -991,142 +1219,20 @@ func hypervisorVendorID() (Vendor, string) {
 	return vend, v
 }
 
-func cacheLine() int {
-	if maxFunctionID() < 0x1 {
+func (c *CPUInfo) cacheLine() int {
+	if c.maxFunc < 0x1 {
 		return 0
 	}
 
-	_, ebx, _, _ := cpuid(1)
+	_, ebx, _, _ := c.src.CPUID(1)
 	cache := (ebx & 0xff00) >> 5 // cflush size
-	if cache == 0 && maxExtendedFunction() >= 0x80000006 {
-		_, _, ecx, _ := cpuid(0x80000006)
+	if cache == 0 && c.maxExFunc >= 0x80000006 {
+		_, _, ecx, _ := c.src.CPUID(0x80000006)
 		cache = ecx & 0xff // cacheline size
 	}
-	// TODO: Read from Cache and TLB Information
 	return int(cache)
 }
 
-func (c *CPUInfo) cacheSize() {
-	c.Cache.L1D = -1
-	c.Cache.L1I = -1
-	c.Cache.L2 = -1
-	c.Cache.L3 = -1
-	vendor, _ := vendorID()
-	switch vendor {
-	case Intel:
-		if maxFunctionID() < 4 {
-			return
-		}
-		c.Cache.L1I, c.Cache.L1D, c.Cache.L2, c.Cache.L3 = 0, 0, 0, 0
-		for i := uint32(0); ; i++ {
-			eax, ebx, ecx, _ := cpuidex(4, i)
-			cacheType := eax & 15
-			if cacheType == 0 {
-				break
-			}
-			cacheLevel := (eax >> 5) & 7
-			coherency := int(ebx&0xfff) + 1
-			partitions := int((ebx>>12)&0x3ff) + 1
-			associativity := int((ebx>>22)&0x3ff) + 1
-			sets := int(ecx) + 1
-			size := associativity * partitions * coherency * sets
-			switch cacheLevel {
-			case 1:
-				if cacheType == 1 {
-					// 1 = Data Cache
-					c.Cache.L1D = size
-				} else if cacheType == 2 {
-					// 2 = Instruction Cache
-					c.Cache.L1I = size
-				} else {
-					if c.Cache.L1D < 0 {
-						c.Cache.L1I = size
-					}
-					if c.Cache.L1I < 0 {
-						c.Cache.L1I = size
-					}
-				}
-			case 2:
-				c.Cache.L2 = size
-			case 3:
-				c.Cache.L3 = size
-			}
-		}
-	case AMD, Hygon:
-		// Untested.
-		if maxExtendedFunction() < 0x80000005 {
-			return
-		}
-		_, _, ecx, edx := cpuid(0x80000005)
-		c.Cache.L1D = int(((ecx >> 24) & 0xFF) * 1024)
-		c.Cache.L1I = int(((edx >> 24) & 0xFF) * 1024)
-
-		if maxExtendedFunction() < 0x80000006 {
-			return
-		}
-		_, _, ecx, _ = cpuid(0x80000006)
-		c.Cache.L2 = int(((ecx >> 16) & 0xFFFF) * 1024)
-
-		// CPUID Fn8000_001D_EAX_x[N:0] Cache Properties
-		if maxExtendedFunction() < 0x8000001D || !c.Has(TOPEXT) {
-			return
-		}
-
-		// Xen Hypervisor is buggy and returns the same entry no matter ECX value.
-		// Hack: When we encounter the same entry 100 times we break.
-		nSame := 0
-		var last uint32
-		for i := uint32(0); i < math.MaxUint32; i++ {
-			eax, ebx, ecx, _ := cpuidex(0x8000001D, i)
-
-			level := (eax >> 5) & 7
-			cacheNumSets := ecx + 1
-			cacheLineSize := 1 + (ebx & 2047)
-			cachePhysPartitions := 1 + ((ebx >> 12) & 511)
-			cacheNumWays := 1 + ((ebx >> 22) & 511)
-
-			typ := eax & 15
-			size := int(cacheNumSets * cacheLineSize * cachePhysPartitions * cacheNumWays)
-			if typ == 0 {
-				return
-			}
-
-			// Check for the same value repeated.
-			comb := eax ^ ebx ^ ecx
-			if comb == last {
-				nSame++
-				if nSame == 100 {
-					return
-				}
-			}
-			last = comb
-
-			switch level {
-			case 1:
-				switch typ {
-				case 1:
-					// Data cache
-					c.Cache.L1D = size
-				case 2:
-					// Inst cache
-					c.Cache.L1I = size
-				default:
-					if c.Cache.L1D < 0 {
-						c.Cache.L1I = size
-					}
-					if c.Cache.L1I < 0 {
-						c.Cache.L1I = size
-					}
-				}
-			case 2:
-				c.Cache.L2 = size
-			case 3:
-				c.Cache.L3 = size
-			}
-		}
-	}
-}
-
 type SGXEPCSection struct {
 	BaseAddress uint64
 	EPCSize     uint64
@@ -1142,7 +1248,7 @@ type SGXSupport struct {
 	EPCSections         []SGXEPCSection
 }
 
-func hasSGX(available, lc bool) (rval SGXSupport) {
+func (c *CPUInfo) hasSGX(available, lc bool) (rval SGXSupport) {
 	rval.Available = available
 
 	if !available {
@@ -1151,7 +1257,7 @@ func hasSGX(available, lc bool) (rval SGXSupport) {
 
 	rval.LaunchControl = lc
 
-	a, _, _, d := cpuidex(0x12, 0)
+	a, _, _, d := c.src.CPUIDEX(0x12, 0)
 	rval.SGX1Supported = a&0x01 != 0
 	rval.SGX2Supported = a&0x02 != 0
 	rval.MaxEnclaveSizeNot64 = 1 << (d & 0xFF)     // pow 2
@@ -1159,7 +1265,7 @@ func hasSGX(available, lc bool) (rval SGXSupport) {
 	rval.EPCSections = make([]SGXEPCSection, 0)
 
 	for subleaf := uint32(2); subleaf < 2+8; subleaf++ {
-		eax, ebx, ecx, edx := cpuidex(0x12, subleaf)
+		eax, ebx, ecx, edx := c.src.CPUIDEX(0x12, subleaf)
 		leafType := eax & 0xf
 
 		if leafType == 0 {
@@ -1187,33 +1293,33 @@ type AMDMemEncryptionSupport struct {
 	MinSevNoEsAsid     uint32
 }
 
-func hasAMDMemEncryption(available bool) (rval AMDMemEncryptionSupport) {
+func (c *CPUInfo) hasAMDMemEncryption(available bool) (rval AMDMemEncryptionSupport) {
 	rval.Available = available
 	if !available {
 		return
 	}
 
-	_, b, c, d := cpuidex(0x8000001f, 0)
+	_, ebx, ecx, edx := c.src.CPUIDEX(0x8000001f, 0)
 
-	rval.CBitPossition = b & 0x3f
-	rval.PhysAddrReduction = (b >> 6) & 0x3F
-	rval.NumVMPL = (b >> 12) & 0xf
-	rval.NumEntryptedGuests = c
-	rval.MinSevNoEsAsid = d
+	rval.CBitPossition = ebx & 0x3f
+	rval.PhysAddrReduction = (ebx >> 6) & 0x3F
+	rval.NumVMPL = (ebx >> 12) & 0xf
+	rval.NumEntryptedGuests = ecx
+	rval.MinSevNoEsAsid = edx
 
 	return
 }
 
-func support() flagSet {
+func support(info *CPUInfo) flagSet {
 	var fs flagSet
-	mfi := maxFunctionID()
-	vend, _ := vendorID()
+	mfi := info.maxFunc
+	vend := info.VendorID
 	if mfi < 0x1 {
 		return fs
 	}
-	family, model, _ := familyModel()
+	family, model := info.Family, info.Model
 
-	_, _, c, d := cpuid(1)
+	_, _, c, d := info.src.CPUID(1)
 	fs.setIf((d&(1<<0)) != 0, X87)
 	fs.setIf((d&(1<<8)) != 0, CMPXCHG8)
 	fs.setIf((d&(1<<11)) != 0, SYSEE)
@@ -1241,10 +1347,10 @@ func support() flagSet {
 	fs.setIf(c&(1<<13) != 0, CX16)
 
 	if vend == Intel && (d&(1<<28)) != 0 && mfi >= 4 {
-		fs.setIf(threadsPerCore() > 1, HTT)
+		fs.setIf(info.ThreadsPerCore > 1, HTT)
 	}
 	if vend == AMD && (d&(1<<28)) != 0 && mfi >= 4 {
-		fs.setIf(threadsPerCore() > 1, HTT)
+		fs.setIf(info.ThreadsPerCore > 1, HTT)
 	}
 	fs.setIf(c&1<<26 != 0, XSAVE)
 	fs.setIf(c&1<<27 != 0, OSXSAVE)
@@ -1252,7 +1358,7 @@ func support() flagSet {
 	const avxCheck = 1<<26 | 1<<27 | 1<<28
 	if c&avxCheck == avxCheck {
 		// Check for OS support
-		eax, _ := xgetbv(0)
+		eax, _ := info.src.XGETBV(0)
 		if (eax & 0x6) == 0x6 {
 			fs.set(AVX)
 			switch vend {
@@ -1272,7 +1378,7 @@ func support() flagSet {
 
 	// Check AVX2, AVX2 requires OS support, but BMI1/2 don't.
 	if mfi >= 7 {
-		_, ebx, ecx, edx := cpuidex(7, 0)
+		_, ebx, ecx, edx := info.src.CPUIDEX(7, 0)
 		if fs.inSet(AVX) && (ebx&0x00000020) != 0 {
 			fs.set(AVX2)
 		}
@@ -1322,7 +1428,7 @@ func support() flagSet {
 		fs.setIf(edx&(1<<31) != 0, SPEC_CTRL_SSBD)
 
 		// CPUID.(EAX=7, ECX=1).EAX
-		eax1, _, _, edx1 := cpuidex(7, 1)
+		eax1, _, _, edx1 := info.src.CPUIDEX(7, 1)
 		fs.setIf(fs.inSet(AVX) && eax1&(1<<4) != 0, AVXVNNI)
 		fs.setIf(eax1&(1<<1) != 0, SM3_X86)
 		fs.setIf(eax1&(1<<2) != 0, SM4_X86)
@@ -1348,13 +1454,13 @@ func support() flagSet {
 		// Only detect AVX-512 features if XGETBV is supported
 		if c&((1<<26)|(1<<27)) == (1<<26)|(1<<27) {
 			// Check for OS support
-			eax, _ := xgetbv(0)
+			eax, _ := info.src.XGETBV(0)
 
 			// Verify that XCR0[7:5] = ‘111b’ (OPMASK state, upper 256-bit of ZMM0-ZMM15 and
 			// ZMM16-ZMM31 state are enabled by OS)
 			/// and that XCR0[2:1] = ‘11b’ (XMM state and YMM state are enabled by OS).
 			hasAVX512 := (eax>>5)&7 == 7 && (eax>>1)&3 == 3
-			if runtime.GOOS == "darwin" {
+			if _, native := info.src.(nativeSource); native && runtime.GOOS == "darwin" {
 				hasAVX512 = fs.inSet(AVX) && darwinHasAVX512()
 			}
 			if hasAVX512 {
@@ -1368,7 +1474,6 @@ func support() flagSet {
 				fs.setIf(ebx&(1<<31) != 0, AVX512VL)
 				// ecx
 				fs.setIf(ecx&(1<<1) != 0, AVX512VBMI)
-				fs.setIf(ecx&(1<<3) != 0, AMXFP8)
 				fs.setIf(ecx&(1<<6) != 0, AVX512VBMI2)
 				fs.setIf(ecx&(1<<11) != 0, AVX512VNNI)
 				fs.setIf(ecx&(1<<12) != 0, AVX512BITALG)
@@ -1379,6 +1484,11 @@ func support() flagSet {
 				fs.setIf(edx&(1<<23) != 0, AVX512FP16)
 				fs.setIf(edx&(1<<24) != 0, AMXTILE)
 				fs.setIf(edx&(1<<25) != 0, AMXINT8)
+				if mfi >= 0x1e {
+					// CPUID.(EAX=1EH, ECX=1).EAX
+					eax1e, _, _, _ := info.src.CPUIDEX(0x1e, 1)
+					fs.setIf(fs.inSet(AMXTILE) && eax1e&(1<<4) != 0, AMXFP8)
+				}
 				// eax1 = CPUID.(EAX=7, ECX=1).EAX
 				fs.setIf(eax1&(1<<5) != 0, AVX512BF16)
 				fs.setIf(eax1&(1<<19) != 0, WRMSRNS)
@@ -1388,7 +1498,7 @@ func support() flagSet {
 		}
 
 		// CPUID.(EAX=7, ECX=2)
-		_, _, _, edx = cpuidex(7, 2)
+		_, _, _, edx = info.src.CPUIDEX(7, 2)
 		fs.setIf(edx&(1<<0) != 0, PSFD)
 		fs.setIf(edx&(1<<1) != 0, IDPRED_CTRL)
 		fs.setIf(edx&(1<<2) != 0, RRSBA_CTRL)
@@ -1396,19 +1506,19 @@ func support() flagSet {
 		fs.setIf(edx&(1<<5) != 0, MCDT_NO)
 
 		if fs.inSet(SGX) {
-			eax, _, _, _ := cpuidex(0x12, 0)
+			eax, _, _, _ := info.src.CPUIDEX(0x12, 0)
 			fs.setIf(eax&(1<<12) != 0, SGXPQC)
 		}
 
 		// Add keylocker features.
 		if fs.inSet(KEYLOCKER) && mfi >= 0x19 {
-			_, ebx, _, _ := cpuidex(0x19, 0)
+			_, ebx, _, _ := info.src.CPUIDEX(0x19, 0)
 			fs.setIf(ebx&5 == 5, KEYLOCKERW) // Bit 0 and 2 (1+4)
 		}
 
 		// Add AVX10 features.
 		if fs.inSet(AVX10) && mfi >= 0x24 {
-			_, ebx, _, _ := cpuidex(0x24, 0)
+			_, ebx, _, _ := info.src.CPUIDEX(0x24, 0)
 			fs.setIf(ebx&(1<<16) != 0, AVX10_128)
 			fs.setIf(ebx&(1<<17) != 0, AVX10_256)
 			fs.setIf(ebx&(1<<18) != 0, AVX10_512)
@@ -1431,15 +1541,15 @@ func support() flagSet {
 	// Bits 07 - 00: Used for XCR0. Bit 08: PT state. Bit 09: Used for XCR0. Bits 12 - 10: Reserved. Bit 13: HWP state. Bits 31 - 14: Reserved.
 	if mfi >= 0xd {
 		if fs.inSet(XSAVE) {
-			eax, _, _, _ := cpuidex(0xd, 1)
+			eax, _, _, _ := info.src.CPUIDEX(0xd, 1)
 			fs.setIf(eax&(1<<0) != 0, XSAVEOPT)
 			fs.setIf(eax&(1<<1) != 0, XSAVEC)
 			fs.setIf(eax&(1<<2) != 0, XGETBV1)
 			fs.setIf(eax&(1<<3) != 0, XSAVES)
 		}
 	}
-	if maxExtendedFunction() >= 0x80000001 {
-		_, _, c, d := cpuid(0x80000001)
+	if info.maxExFunc >= 0x80000001 {
+		_, _, c, d := info.src.CPUID(0x80000001)
 		if (c & (1 << 5)) != 0 {
 			fs.set(LZCNT)
 			fs.set(POPCNT)
@@ -1470,16 +1580,16 @@ func support() flagSet {
 		}
 
 	}
-	if maxExtendedFunction() >= 0x80000007 {
-		_, b, _, d := cpuid(0x80000007)
+	if info.maxExFunc >= 0x80000007 {
+		_, b, _, d := info.src.CPUID(0x80000007)
 		fs.setIf((b&(1<<0)) != 0, MCAOVERFLOW)
 		fs.setIf((b&(1<<1)) != 0, SUCCOR)
 		fs.setIf((b&(1<<2)) != 0, HWA)
 		fs.setIf((d&(1<<9)) != 0, CPBOOST)
 	}
 
-	if maxExtendedFunction() >= 0x80000008 {
-		_, b, _, _ := cpuid(0x80000008)
+	if info.maxExFunc >= 0x80000008 {
+		_, b, _, _ := info.src.CPUID(0x80000008)
 		fs.setIf(b&(1<<28) != 0, PSFD)
 		fs.setIf(b&(1<<27) != 0, CPPC)
 		fs.setIf(b&(1<<24) != 0, SPEC_CTRL_SSBD)
@@ -1501,8 +1611,8 @@ func support() flagSet {
 		fs.setIf((b&(1<<0)) != 0, CLZERO)
 	}
 
-	if fs.inSet(SVM) && maxExtendedFunction() >= 0x8000000A {
-		_, _, _, edx := cpuid(0x8000000A)
+	if fs.inSet(SVM) && info.maxExFunc >= 0x8000000A {
+		_, _, _, edx := info.src.CPUID(0x8000000A)
 		fs.setIf((edx>>0)&1 == 1, SVMNP)
 		fs.setIf((edx>>1)&1 == 1, LBRVIRT)
 		fs.setIf((edx>>2)&1 == 1, SVML)
@@ -1515,15 +1625,15 @@ func support() flagSet {
 		fs.setIf((edx>>12)&1 == 1, SVMPFT)
 	}
 
-	if maxExtendedFunction() >= 0x8000001a {
-		eax, _, _, _ := cpuid(0x8000001a)
+	if info.maxExFunc >= 0x8000001a {
+		eax, _, _, _ := info.src.CPUID(0x8000001a)
 		fs.setIf((eax>>0)&1 == 1, FP128)
 		fs.setIf((eax>>1)&1 == 1, MOVU)
 		fs.setIf((eax>>2)&1 == 1, FP256)
 	}
 
-	if maxExtendedFunction() >= 0x8000001b && fs.inSet(IBS) {
-		eax, _, _, _ := cpuid(0x8000001b)
+	if info.maxExFunc >= 0x8000001b && fs.inSet(IBS) {
+		eax, _, _, _ := info.src.CPUID(0x8000001b)
 		fs.setIf((eax>>0)&1 == 1, IBSFFV)
 		fs.setIf((eax>>1)&1 == 1, IBSFETCHSAM)
 		fs.setIf((eax>>2)&1 == 1, IBSOPSAM)
@@ -1538,8 +1648,8 @@ func support() flagSet {
 		fs.setIf((eax>>11)&1 == 1, IBS_ZEN4)
 	}
 
-	if maxExtendedFunction() >= 0x8000001f && vend == AMD {
-		a, _, _, _ := cpuid(0x8000001f)
+	if info.maxExFunc >= 0x8000001f && vend == AMD {
+		a, _, _, _ := info.src.CPUID(0x8000001f)
 		fs.setIf((a>>0)&1 == 1, SME)
 		fs.setIf((a>>1)&1 == 1, SEV)
 		fs.setIf((a>>2)&1 == 1, MSR_PAGEFLUSH)
@@ -1556,8 +1666,8 @@ func support() flagSet {
 		fs.setIf((a>>24)&1 == 1, VMSA_REGPROT)
 	}
 
-	if maxExtendedFunction() >= 0x80000021 && vend == AMD {
-		a, _, c, _ := cpuid(0x80000021)
+	if info.maxExFunc >= 0x80000021 && vend == AMD {
+		a, _, c, _ := info.src.CPUID(0x80000021)
 		fs.setIf((a>>31)&1 == 1, SRSO_MSR_FIX)
 		fs.setIf((a>>30)&1 == 1, SRSO_USER_KERNEL_NO)
 		fs.setIf((a>>29)&1 == 1, SRSO_NO)
@@ -1592,13 +1702,13 @@ func support() flagSet {
 		// For Intel TDX, `ebx` is set as `0xbe3`, being 3 the part
 		// we're mostly interested about,according to:
 		// https://github.com/torvalds/linux/blob/d2f51b3516dade79269ff45eae2a7668ae711b25/arch/x86/include/asm/hyperv-tlfs.h#L169-L174
-		_, ebx, _, _ := cpuid(0x4000000C)
+		_, ebx, _, _ := info.src.CPUID(0x4000000C)
 		fs.setIf(ebx == 0xbe3, TDX_GUEST)
 	}
 
 	if mfi >= 0x21 {
 		// Intel Trusted Domain Extensions Guests have their own cpuid leaf (0x21).
-		_, ebx, ecx, edx := cpuid(0x21)
+		_, ebx, ecx, edx := info.src.CPUID(0x21)
 		identity := string(valAsString(ebx, edx, ecx))
 		fs.setIf(identity == "IntelTDX    ", TDX_GUEST)
 	}
@@ -1608,7 +1718,7 @@ func support() flagSet {
 
 func (c *CPUInfo) supportAVX10() uint8 {
 	if c.maxFunc >= 0x24 && c.featureSet.inSet(AVX10) {
-		_, ebx, _, _ := cpuidex(0x24, 0)
+		_, ebx, _, _ := c.src.CPUIDEX(0x24, 0)
 		return uint8(ebx)
 	}
 	return 0
@@ -42,6 +42,6 @@ TEXT ·asmRdtscpAsm(SB), 7, $0
 	RET
 
 // func asmDarwinHasAVX512() bool
-TEXT ·asmDarwinHasAVX512(SB), 7, $0
-	MOVL $0, eax+0(FP)
+TEXT ·asmDarwinHasAVX512(SB), 7, $0-1
+	MOVB $0, ret+0(FP)
 	RET
@@ -3,10 +3,17 @@
 package cpuid
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"reflect"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 )
 
 func TestLastID(t *testing.T) {
@@ -25,9 +32,9 @@ func TestLastVendorID(t *testing.T) {
 // obviously differ on each machine.
 func TestCPUID(t *testing.T) {
 	Detect()
-	n := maxFunctionID()
+	n := maxFunctionID(nativeSource{})
 	t.Logf("Max Function:0x%x", n)
-	n = maxExtendedFunction()
+	n = maxExtendedFunction(nativeSource{})
 	t.Logf("Max Extended Function:0x%x", n)
 	t.Log("VendorString:", CPU.VendorString)
 	t.Log("VendorID:", CPU.VendorID)
@@ -69,7 +76,7 @@ func TestExample(t *testing.T) {
 	}
 }
 func TestDumpCPUID(t *testing.T) {
-	n := int(maxFunctionID())
+	n := int(maxFunctionID(nativeSource{}))
 	for i := 0; i <= n; i++ {
 		a, b, c, d := cpuidex(uint32(i), 0)
 		t.Logf("CPUID %08x: %08x-%08x-%08x-%08x", i, a, b, c, d)
@@ -84,7 +91,7 @@ func TestDumpCPUID(t *testing.T) {
 			ex++
 		}
 	}
-	n2 := maxExtendedFunction()
+	n2 := maxExtendedFunction(nativeSource{})
 	for i := uint32(0x80000000); i <= n2; i++ {
 		a, b, c, d := cpuid(i)
 		t.Logf("CPUID %08x: %08x-%08x-%08x-%08x", i, a, b, c, d)
@@ -147,9 +154,307 @@ func TestAMDMemEncryption(t *testing.T) {
 	t.Log("AMDMemEncryption Support:", got)
 }
 
+func TestCurrent(t *testing.T) {
+	Detect()
+	defer Detect()
+	c := Current()
+	if c == nil {
+		t.Fatal("no current snapshot")
+	}
+	if !reflect.DeepEqual(c.FeatureSet(), CPU.FeatureSet()) {
+		t.Fatalf("snapshot mismatch: %v != %v", c.FeatureSet(), CPU.FeatureSet())
+	}
+	r := Redetect()
+	if r == c || r == Current() {
+		t.Fatal("snapshot is shared")
+	}
+	if !reflect.DeepEqual(r.FeatureSet(), c.FeatureSet()) {
+		t.Fatalf("redetect mismatch: %v != %v", r.FeatureSet(), c.FeatureSet())
+	}
+	// Changing returned values must not change the snapshot.
+	c.Disable(SSE2)
+	r.Disable(SSE2)
+	if Current().Has(SSE2) != CPU.Has(SSE2) {
+		t.Fatal("snapshot was modified")
+	}
+	u := Update(func(c *CPUInfo) { c.Disable(SSE2) })
+	if u.Has(SSE2) || Current().Has(SSE2) {
+		t.Fatal("Update did not publish the change")
+	}
+	u.Enable(SSE2)
+	if Current().Has(SSE2) {
+		t.Fatal("snapshot returned by Update was modified")
+	}
+}
+
+func TestWithDisabled(t *testing.T) {
+	Detect()
+	defer Detect()
+	before := Current()
+	feats := before.FeatureSet()
+	var wg sync.WaitGroup
+	for _, feat := range feats {
+		f := ParseFeature(feat)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if c := WithDisabled(f); c.Has(f) {
+				t.Errorf("%v not disabled", f)
+			}
+			_ = Current().Has(f)
+		}()
+	}
+	wg.Wait()
+	if n := len(Current().FeatureSet()); n != 0 {
+		t.Errorf("expected all features disabled, got %v", Current().FeatureSet())
+	}
+	if !reflect.DeepEqual(before.FeatureSet(), feats) {
+		t.Error("previous snapshot was modified")
+	}
+	if !reflect.DeepEqual(CPU.FeatureSet(), feats) {
+		t.Error("CPU was modified")
+	}
+}
+
+func TestMarshalText(t *testing.T) {
+	for f := firstID + 1; f < lastID; f++ {
+		b, err := f.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got FeatureID
+		if err := got.UnmarshalText(b); err != nil || got != f {
+			t.Errorf("feature %v: got %v, err %v", f, got, err)
+		}
+	}
+	for v := VendorUnknown; v < lastVendor; v++ {
+		b, err := v.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Vendor
+		if err := got.UnmarshalText(b); err != nil || got != v {
+			t.Errorf("vendor %v: got %v, err %v", v, got, err)
+		}
+	}
+	var f FeatureID
+	if err := f.UnmarshalText([]byte("NOT_A_FEATURE")); err == nil {
+		t.Error("expected error on unknown feature")
+	}
+	var v Vendor
+	if err := v.UnmarshalText([]byte("NOT_A_VENDOR")); err == nil {
+		t.Error("expected error on unknown vendor")
+	}
+
+	type types struct {
+		Cache    CacheType
+		TLB      TLBType
+		Topology TopologyType
+	}
+	for _, want := range []types{{}, {CacheTypeUnified, TLBTypeStore, TopologyPackage}, {CacheTypeData, TLBTypeLoad, TopologyDieGroup}} {
+		b, err := json.Marshal(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got types
+		if err := json.Unmarshal(b, &got); err != nil || got != want {
+			t.Errorf("%s: got %+v, err %v", b, got, err)
+		}
+	}
+	b, _ := json.Marshal(types{CacheTypeInstruction, TLBTypeData, TopologyCore})
+	if want := `{"Cache":"Instruction","TLB":"Data","Topology":"Core"}`; string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+	var got types
+	for _, s := range []string{`{"Cache":"L1"}`, `{"TLB":"Code"}`, `{"Topology":"Node"}`} {
+		if err := json.Unmarshal([]byte(s), &got); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+func TestFeatureCodes(t *testing.T) {
+	seen := make(map[uint16]FeatureID)
+	for f := firstID + 1; f < lastID; f++ {
+		code := f.Code()
+		if code == 0 {
+			t.Errorf("%v has no wire code", f)
+			continue
+		}
+		if other, ok := seen[code]; ok {
+			t.Errorf("%v and %v share wire code %d", f, other, code)
+		}
+		seen[code] = f
+		if got := FeatureFromCode(code); got != f {
+			t.Errorf("FeatureFromCode(%d): got %v, want %v", code, got, f)
+		}
+	}
+	// Codes must never change.
+	for f, code := range map[FeatureID]uint16{ADX: 1, AVX2: 19, SSE42: 163, SVE: 233} {
+		if f.Code() != code {
+			t.Errorf("%v: wire code changed from %d to %d", f, code, f.Code())
+		}
+	}
+	seenV := make(map[uint8]Vendor)
+	for v := VendorUnknown; v < lastVendor; v++ {
+		if other, ok := seenV[v.code()]; ok {
+			t.Errorf("%v and %v share wire code %d", v, other, v.code())
+		}
+		seenV[v.code()] = v
+		if got := vendorFromCode(v.code()); got != v {
+			t.Errorf("vendorFromCode(%d): got %v, want %v", v.code(), got, v)
+		}
+	}
+}
+
+func TestFeatureSet(t *testing.T) {
+	a := NewFeatureSet(SSE, SSE2, AVX)
+	b := NewFeatureSet(AVX, AVX2)
+	if got := a.Union(b).String(); got != "AVX,AVX2,SSE,SSE2" {
+		t.Errorf("union: got %q", got)
+	}
+	if got := a.Intersect(b).String(); got != "AVX" {
+		t.Errorf("intersect: got %q", got)
+	}
+	if got := a.Difference(b).String(); got != "SSE,SSE2" {
+		t.Errorf("difference: got %q", got)
+	}
+	if !a.Contains(SSE2) || a.Contains(AVX2) || a.Contains(UNKNOWN) || a.Contains(lastID) {
+		t.Error("unexpected Contains result")
+	}
+	if !a.ContainsAll(NewFeatureSet(SSE, AVX)) || a.ContainsAll(b) {
+		t.Error("unexpected ContainsAll result")
+	}
+	if a.Len() != 3 || (FeatureSet{}).Len() != 0 {
+		t.Error("unexpected length", a.Len())
+	}
+	if !reflect.DeepEqual(a.IDs(), []FeatureID{AVX, SSE, SSE2}) {
+		t.Errorf("unexpected IDs: %v", a.IDs())
+	}
+	got, err := ParseFeatureSet(" sse2, AVX,SSE ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != a {
+		t.Errorf("parse: got %v, want %v", got, a)
+	}
+	if _, err := ParseFeatureSet("SSE,NOT_A_FEATURE"); err == nil {
+		t.Error("expected error on unknown feature")
+	}
+	if f, err := ParseFeatureSet(""); err != nil || f.Len() != 0 {
+		t.Error("expected empty set", f, err)
+	}
+	cpu := CPU.Features()
+	if cpu.String() != strings.Join(CPU.FeatureSet(), ",") {
+		t.Errorf("CPU features mismatch: %v != %v", cpu, CPU.FeatureSet())
+	}
+	if cpu.Len() != len(CPU.FeatureSet()) {
+		t.Errorf("CPU feature count mismatch: %v != %v", cpu.Len(), len(CPU.FeatureSet()))
+	}
+}
+
+func TestFeatureInfo(t *testing.T) {
+	for f := firstID + 1; f < lastID; f++ {
+		info := f.Info()
+		if info.Name != f.String() {
+			t.Errorf("%v: unexpected name %q", f, info.Name)
+		}
+		if info.Description == "" {
+			t.Errorf("%v: no description", f)
+		}
+		if info.Arch == ArchUnknown {
+			t.Errorf("%v: no architecture", f)
+		}
+	}
+	info := AVX2.Info()
+	if info.Arch != ArchX86 || info.Category != CategorySIMD || len(info.Origins) != 1 {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	if got := info.Origins[0].String(); got != "CPUID.(EAX=07H,ECX=0):EBX[5]" {
+		t.Errorf("unexpected origin: %s", got)
+	}
+	info = AESARM.Info()
+	if info.Arch != ArchARM64 || info.Category != CategoryCrypto {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	var origins []string
+	for _, o := range info.Origins {
+		origins = append(origins, o.String())
+	}
+	if got := strings.Join(origins, ","); got != "ID_AA64ISAR0_EL1[7:4],HWCAP[3]" {
+		t.Errorf("unexpected origins: %s", got)
+	}
+	if SPEC_CTRL_SSBD.Info().Category != CategorySecurity || SEV.Info().Category != CategoryVirtualization {
+		t.Error("unexpected category")
+	}
+	if FeatureID(UNKNOWN).Info().Description != "" {
+		t.Error("unexpected info for UNKNOWN")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	b, err := json.Marshal(CPU)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got CPUInfo
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.FeatureSet(), CPU.FeatureSet()) {
+		t.Errorf("features mismatch: %v != %v", got.FeatureSet(), CPU.FeatureSet())
+	}
+	if got.VendorID != CPU.VendorID || got.BrandName != CPU.BrandName || got.Cache != CPU.Cache {
+		t.Errorf("mismatch: %+v != %+v", got, CPU)
+	}
+	if got.X64Level() != CPU.X64Level() {
+		t.Errorf("level mismatch: %d != %d", got.X64Level(), CPU.X64Level())
+	}
+}
+
+func TestJSONNumericVendor(t *testing.T) {
+	// Output of "cpuid -json" before vendors were written by name.
+	const old = `{
+  "BrandName": "AMD Ryzen 9 3900X 12-Core Processor",
+  "VendorID": 2,
+  "VendorString": "AuthenticAMD",
+  "HypervisorVendorID": 0,
+  "HypervisorVendorString": "",
+  "PhysicalCores": 12,
+  "ThreadsPerCore": 2,
+  "LogicalCores": 24,
+  "Family": 23,
+  "Model": 113,
+  "Stepping": 0,
+  "CacheLine": 64,
+  "Hz": 3800000000,
+  "BoostFreq": 4600000000,
+  "Cache": {"L1I": 32768, "L1D": 32768, "L2": 524288, "L3": 16777216},
+  "SGX": {"Available": false, "LaunchControl": false, "SGX1Supported": false, "SGX2Supported": false, "MaxEnclaveSizeNot64": 0, "MaxEnclaveSize64": 0, "EPCSections": null},
+  "AVX10Level": 0,
+  "Features": ["ADX", "AESNI", "AVX", "AVX2", "BMI1", "BMI2", "CMOV", "CX16", "FMA3", "LZCNT", "MMX", "MOVBE", "OSXSAVE", "POPCNT", "SSE", "SSE2", "SSE3", "SSE4", "SSE42", "SSSE3", "X87", "XSAVE"],
+  "X64Level": 3
+}`
+	var c CPUInfo
+	if err := json.Unmarshal([]byte(old), &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.VendorID != AMD || c.HypervisorVendorID != VendorUnknown || c.Cache.L3 != 16<<20 {
+		t.Errorf("got %+v", c)
+	}
+	if !c.Supports(AVX2, BMI2) {
+		t.Errorf("got features %v", c.FeatureSet())
+	}
+	if err := json.Unmarshal([]byte(`{"VendorID": 1000}`), &c); err == nil {
+		t.Error("want error for unknown vendor number")
+	}
+}
+
 func TestHas(t *testing.T) {
 	Detect()
 	defer Detect()
+	// Features detected without their prerequisites cannot be enabled again.
+	CPU.DisableUnsupported()
 	feats := CPU.FeatureSet()
 	for _, feat := range feats {
 		f := ParseFeature(feat)
@@ -163,25 +468,81 @@ func TestHas(t *testing.T) {
 		if !CPU.Supports(f) {
 			t.Error("CPU.Supports returned false, want true")
 		}
-		// Disable it.
-		CPU.Disable(f)
-		if CPU.Has(f) {
+		// Disable it on a copy, since dependent features are also disabled.
+		c := CPU
+		c.Disable(f)
+		if c.Has(f) {
 			t.Error("CPU.Has returned true, want false")
 		}
-		if CPU.Supports(f) {
+		if c.Supports(f) {
 			t.Error("CPU.Supports returned true, want false")
 		}
 		// Reenable
-		CPU.Enable(f)
-		if !CPU.Has(f) {
+		if !c.Enable(f) {
+			t.Error("CPU.Enable returned false, missing", c.MissingPrerequisites(f))
+		}
+		if !c.Has(f) {
 			t.Error("CPU.Has returned false, want true")
 		}
-		if !CPU.Supports(f) {
+		if !c.Supports(f) {
 			t.Error("CPU.Supports returned false, want true")
 		}
 	}
 }
 
+func TestFeatureDeps(t *testing.T) {
+	var c CPUInfo
+	c.featureSet.setIf(true, OSXSAVE, XSAVE, AVX, AVX2, FMA3, AVX512F, AVX512BW, AVX512VBMI, VAES, AVXVNNI, SSE2, AESNI)
+	c.Disable(AVX)
+	for _, f := range []FeatureID{AVX, AVX2, FMA3, AVX512F, AVX512BW, AVX512VBMI, VAES, AVXVNNI} {
+		if c.Has(f) {
+			t.Errorf("%v still enabled after disabling AVX", f)
+		}
+	}
+	for _, f := range []FeatureID{OSXSAVE, XSAVE, SSE2, AESNI} {
+		if !c.Has(f) {
+			t.Errorf("%v was disabled", f)
+		}
+	}
+
+	if c.Enable(AVX2) {
+		t.Error("AVX2 enabled without AVX")
+	}
+	if c.Has(AVX2) {
+		t.Error("AVX2 enabled after refused Enable")
+	}
+	if got := c.MissingPrerequisites(AVX2); got.String() != "AVX" {
+		t.Errorf("missing: got %v, want AVX", got)
+	}
+	if got := c.MissingPrerequisites(AVX, AVX2); got.Len() != 0 {
+		t.Errorf("missing: got %v, want none", got)
+	}
+	if !c.Enable(AVX, AVX2) || !c.Has(AVX2) {
+		t.Error("could not enable AVX and AVX2")
+	}
+
+	imp := Implies(AVX512VBMI)
+	if !imp.ContainsAll(NewFeatureSet(AVX512BW, AVX512F, AVX2, AVX, OSXSAVE, XSAVE)) {
+		t.Errorf("Implies(AVX512VBMI) = %v", imp)
+	}
+	if imp.Contains(AVX512VBMI) || imp.Contains(SSE2) {
+		t.Errorf("Implies(AVX512VBMI) = %v", imp)
+	}
+	if !Implies(SVE).ContainsAll(NewFeatureSet(ASIMD, FP)) {
+		t.Errorf("Implies(SVE) = %v", Implies(SVE))
+	}
+	if Implies(UNKNOWN).Len() != 0 || Implies(ADX).Len() != 0 {
+		t.Error("unexpected prerequisites")
+	}
+
+	// The graph must not contain cycles.
+	for f := firstID + 1; f < lastID; f++ {
+		if featureRequires[f].inSet(f) {
+			t.Errorf("%v depends on itself", f)
+		}
+	}
+}
+
 // TestSGXLC tests SGX Launch Control detection
 func TestSGXLC(t *testing.T) {
 	got := CPU.SGX.LaunchControl
@@ -232,16 +593,33 @@ func TestLogicalCPU(t *testing.T) {
 }
 
 func TestMaxFunction(t *testing.T) {
-	expect := maxFunctionID()
+	expect := maxFunctionID(nativeSource{})
 	if CPU.maxFunc != expect {
 		t.Fatal("Max function does not match, expected", expect, "but got", CPU.maxFunc)
 	}
-	expect = maxExtendedFunction()
+	expect = maxExtendedFunction(nativeSource{})
 	if CPU.maxExFunc != expect {
 		t.Fatal("Max Extended function does not match, expected", expect, "but got", CPU.maxFunc)
 	}
 }
 
+func TestDump(t *testing.T) {
+	if CPU.maxFunc == 0 {
+		t.Skip("no CPUID leaves")
+	}
+	eax, _, _, _ := CPU.Leaf(0, 0)
+	if eax != CPU.maxFunc {
+		t.Fatalf("leaf 0 does not match, expected %x, got %x", CPU.maxFunc, eax)
+	}
+	var buf strings.Builder
+	if err := CPU.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), fmt.Sprintf("CPUID 00000000: %08X-", eax)) {
+		t.Fatalf("unexpected dump: %s", buf.String())
+	}
+}
+
 // This example will calculate the chip/core number on Linux
 // Linux encodes numa id (<<12) and core id (8bit) into TSC_AUX.
 func ExampleCPUInfo_Ia32TscAux() {
@@ -255,6 +633,543 @@ func ExampleCPUInfo_Ia32TscAux() {
 	fmt.Println("Chip, Core:", chip, core)
 }
 
+func TestRequirement(t *testing.T) {
+	r, err := ParseRequirement("avx2 && BMI2&&(ADX || AVX512IFMA)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.String(), "AVX2 && BMI2 && (ADX || AVX512IFMA)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	var c CPUInfo
+	c.featureSet.setIf(true, AVX2, AVX512IFMA)
+	if r.Eval(&c) {
+		t.Error("requirement satisfied without BMI2")
+	}
+	if got := r.Missing(&c).String(); got != "BMI2" {
+		t.Errorf("missing: got %q, want BMI2", got)
+	}
+	c.featureSet.set(BMI2)
+	if !r.Eval(&c) || r.Missing(&c).Len() != 0 {
+		t.Error("requirement not satisfied")
+	}
+	c = CPUInfo{}
+	if got := r.Missing(&c).String(); got != "ADX,AVX2,BMI2" {
+		t.Errorf("missing: got %q", got)
+	}
+
+	// Absorption and nesting.
+	r = MustParseRequirement("(SSE2 || SSE2 && AVX) && (SSE42 || (SSE4 && SSSE3))")
+	if len(r.terms) != 2 {
+		t.Errorf("got %d terms, want 2", len(r.terms))
+	}
+	if got, want := r.String(), "(SSE2 || SSE2 && AVX) && (SSE42 || SSE4 && SSSE3)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if !(Requirement{}).Eval(&c) {
+		t.Error("zero requirement not satisfied")
+	}
+
+	b, err := r.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r2 Requirement
+	if err := r2.UnmarshalText(b); err != nil || r2.String() != r.String() {
+		t.Errorf("round trip: %v, %q", err, r2.String())
+	}
+
+	for _, bad := range []string{"", "AVX2 &&", "AVX2 & BMI2", "(AVX2", "AVX2)", "AVX2 || NOTAFEATURE", "&& AVX2", "()"} {
+		if _, err := ParseRequirement(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+	// 4^5*2 terms.
+	if _, err := ParseRequirement("(AVX || AVX2 || SSE || SSE2) && (SSE3 || SSSE3 || SSE4 || SSE42) && " +
+		"(BMI1 || BMI2 || ADX || AESNI) && (FMA3 || F16C || MMX || CMOV) && (X87 || LZCNT || POPCNT || CX16) && (AVX512F || AVX512BW)"); err == nil {
+		t.Error("expected too complex error")
+	}
+	// Two terms of 64 features each would multiply to 4096 terms.
+	var left, right []string
+	for id := AESNI; len(right) < 64; id++ {
+		if len(left) < 64 {
+			left = append(left, id.String())
+		} else {
+			right = append(right, id.String())
+		}
+	}
+	_, err = ParseRequirement("(" + strings.Join(left, " || ") + ") && (" + strings.Join(right, " || ") + ")")
+	if err == nil || !strings.Contains(err.Error(), "too complex") {
+		t.Errorf("expected too complex error, got %v", err)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	var base CPUInfo
+	base.featureSet.setIf(true, SYSCALL, CMOV, CMPXCHG8, X87, FXSR, MMX, SSE, SSE2, CX16, LAHF, POPCNT, SSE3, SSE4, SSE42, SSSE3,
+		XSAVE, OSXSAVE, AVX, AVX2, BMI1, BMI2, FMA3, AESNI, AVX10, AVX10_256)
+	base.AVX10Level = 1
+
+	t.Setenv(EnvMaxLevel, "2")
+	t.Setenv(EnvDisable, "sse42, bogus")
+	t.Setenv(EnvEnable, "AESNI")
+	c := base
+	applyEnv(&c)
+	if got := c.X64Level(); got != 1 {
+		t.Errorf("level: got %d, want 1", got)
+	}
+	if c.Has(AVX) || c.Has(AVX10) || c.AVX10Level != 0 || c.Has(SSE42) {
+		t.Errorf("features not masked: %v", c.FeatureSet())
+	}
+	if !c.Has(AESNI) || !c.Has(SSE4) {
+		t.Errorf("features missing: %v", c.FeatureSet())
+	}
+
+	t.Setenv(EnvMaxLevel, "")
+	t.Setenv(EnvDisable, "avx")
+	t.Setenv(EnvEnable, "AVX512F")
+	c = base
+	applyEnv(&c)
+	if c.Has(AVX2) || c.Has(FMA3) || c.Has(AVX512F) || !c.Has(BMI2) {
+		t.Errorf("unexpected features: %v", c.FeatureSet())
+	}
+
+	// Invalid level is ignored.
+	t.Setenv(EnvDisable, "")
+	t.Setenv(EnvEnable, "")
+	t.Setenv(EnvMaxLevel, "5")
+	c = base
+	applyEnv(&c)
+	if c.Features() != base.Features() {
+		t.Errorf("features changed: %v", c.FeatureSet())
+	}
+
+	// Detection applies the environment.
+	t.Setenv(EnvMaxLevel, "")
+	t.Setenv(EnvDisable, "SSE2")
+	if got := detect(); got.Has(SSE2) || got.Has(SSE42) {
+		t.Errorf("detect did not apply %s: %v", EnvDisable, got.FeatureSet())
+	}
+}
+
+func TestParseGODEBUG(t *testing.T) {
+	tests := []struct {
+		godebug, goarch string
+		want            []FeatureID
+	}{
+		{godebug: "", goarch: "amd64"},
+		{godebug: "cpu.avx2=off", goarch: "amd64", want: []FeatureID{AVX2}},
+		{godebug: "http2debug=1, cpu.bmi2=off,cpu.sse41=off,cpu.foo=off", goarch: "amd64", want: []FeatureID{BMI2, SSE4}},
+		{godebug: "cpu.avx2=off,cpu.avx2=on", goarch: "amd64"},
+		{godebug: "cpu.aes=off", goarch: "arm64", want: []FeatureID{AESARM}},
+		{godebug: "cpu.sse2=off", goarch: "amd64"},
+		{godebug: "cpu.sse2=off", goarch: "386", want: []FeatureID{SSE2}},
+		{godebug: "cpu.avx=maybe", goarch: "amd64"},
+		{godebug: "cpu.avx=off", goarch: "riscv64"},
+	}
+	for _, test := range tests {
+		got := parseGODEBUG(test.godebug, test.goarch)
+		if NewFeatureSet(got...) != NewFeatureSet(test.want...) {
+			t.Errorf("%q: got %v, want %v", test.godebug, got, test.want)
+		}
+	}
+
+	// All off, except AVX2 which keeps its prerequisites.
+	got := NewFeatureSet(parseGODEBUG("cpu.all=off,cpu.avx2=on", "amd64")...)
+	if got.Contains(AVX2) || got.Contains(AVX) || got.Contains(OSXSAVE) || got.Contains(SSE2) {
+		t.Errorf("unexpected features disabled: %v", got)
+	}
+	if !got.Contains(AVX512F) || !got.Contains(BMI2) || !got.Contains(SSE42) {
+		t.Errorf("features not disabled: %v", got)
+	}
+}
+
+func TestRuntimeDiscrepancies(t *testing.T) {
+	defer Redetect()
+	for _, d := range RuntimeDiscrepancies() {
+		t.Log("discrepancy:", d)
+	}
+	for _, f := range runtimeFeatures(runtime.GOARCH) {
+		if !*f.v || !Current().Has(f.id) {
+			continue
+		}
+		WithDisabled(f.id)
+		found := false
+		for _, d := range RuntimeDiscrepancies() {
+			if d.Feature == f.id {
+				found = d.Runtime && !d.CPUID
+			}
+		}
+		if !found {
+			t.Errorf("%v not reported after disabling", f.id)
+		}
+		break
+	}
+}
+
+// stringOnlyFlags is a FlagRegistrar without Var.
+type stringOnlyFlags struct{ fs *flag.FlagSet }
+
+func (s stringOnlyFlags) StringVar(p *string, name, value, usage string) {
+	s.fs.StringVar(p, name, value, usage)
+}
+func (s stringOnlyFlags) BoolVar(p *bool, name string, value bool, usage string) {
+	s.fs.BoolVar(p, name, value, usage)
+}
+func (s stringOnlyFlags) IntVar(p *int, name string, value int, usage string) {
+	s.fs.IntVar(p, name, value, usage)
+}
+
+func TestFlagsOn(t *testing.T) {
+	saved := cpuFlags
+	defer func() { cpuFlags = saved }()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	FlagsOn(fs)
+	err := fs.Parse([]string{"-cpu.maxlevel=3", "-cpu.disable=bmi2", "-cpu.enable=sse4a", "-cpu.features"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cpuFlags.features != "true" || cpuFlags.maxLevel != 3 {
+		t.Errorf("unexpected values: %+v", cpuFlags)
+	}
+	if err := fs.Set("cpu.features", "json"); err != nil || cpuFlags.features != "json" {
+		t.Errorf("cpu.features=json: %v, %q", err, cpuFlags.features)
+	}
+	if err := fs.Set("cpu.features", "yaml"); err == nil {
+		t.Error("expected error")
+	}
+	cpuFlags.features = ""
+
+	var c CPUInfo
+	c.featureSet.setIf(true, SYSCALL, CMOV, CMPXCHG8, X87, FXSR, MMX, SSE, SSE2, CX16, LAHF, POPCNT, SSE3, SSE4, SSE42, SSSE3,
+		XSAVE, OSXSAVE, AVX, AVX2, BMI1, BMI2, F16C, FMA3, LZCNT, MOVBE, AVX512F, AVX512BW, AVX512CD, AVX512DQ, AVX512VL, AESNI)
+	applyFlags(&c)
+	if c.Has(AVX512F) || c.Has(AESNI) || c.Has(BMI2) || !c.Has(AVX2) || !c.Has(SSE4A) {
+		t.Errorf("unexpected features: %v", c.FeatureSet())
+	}
+
+	cpuFlags = saved
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	FlagsWith(stringOnlyFlags{fs: fs})
+	if err := fs.Parse([]string{"-cpu.profile=x86-64-v2", "-cpu.features=false"}); err != nil {
+		t.Fatal(err)
+	}
+	c.featureSet.set(AVX512F)
+	applyFlags(&c)
+	if c.X64Level() != 2 {
+		t.Errorf("level: got %d, want 2", c.X64Level())
+	}
+
+	var buf bytes.Buffer
+	if err := printFeatures(&buf, &c, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var got CPUInfo
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil || got.Features() != c.Features() {
+		t.Errorf("json output: %v, %s", err, buf.String())
+	}
+	buf.Reset()
+	if err := printFeatures(&buf, &c, "true"); err != nil || !strings.HasPrefix(buf.String(), "cpu features: CMOV,") {
+		t.Errorf("list output: %v, %q", err, buf.String())
+	}
+}
+
+func TestLimitToProfileLevel(t *testing.T) {
+	want := map[string]int{
+		"x86-64": 1, "x86-64-v2": 2, "x86-64-v3": 3, "x86-64-v4": 4,
+		"nehalem": 2, "sandybridge": 2, "haswell": 3, "skylake": 3, "skylake-avx512": 4,
+		"icelake-server": 4, "sapphirerapids": 4, "graniterapids": 4,
+		"znver1": 3, "znver2": 3, "znver3": 3, "znver4": 4, "znver5": 4,
+	}
+	// A host with the features of all profiles.
+	var host CPUInfo
+	host.featureSet.set(SYSCALL)
+	for _, p := range profiles {
+		host.featureSet.or(*p.features)
+	}
+	for _, name := range Profiles() {
+		level, ok := want[name]
+		if !ok {
+			continue
+		}
+		delete(want, name)
+		p, _ := Profile(name)
+		c := host
+		c.LimitTo(p)
+		if got := c.X64Level(); got != level {
+			t.Errorf("%s: level %d, want %d", name, got, level)
+		}
+	}
+	for name := range want {
+		t.Errorf("profile %s not found", name)
+	}
+}
+
+func TestLimitTo(t *testing.T) {
+	var base CPUInfo
+	base.featureSet.setIf(true, SYSCALL, CMOV, CMPXCHG8, X87, FXSR, MMX, SSE, SSE2, CX16, LAHF, POPCNT, SSE3, SSE4, SSE42, SSSE3,
+		XSAVE, OSXSAVE, AVX, AVX2, BMI1, BMI2, F16C, FMA3, LZCNT, MOVBE, AVX512F, AVX512BW, AVX512CD, AVX512DQ, AVX512VL,
+		AVX10, AVX10_256, AVX10_512, AESNI, SGX, SGXLC)
+	base.AVX10Level = 2
+	base.SGX.Available, base.SGX.LaunchControl = true, true
+	if base.X64Level() != 4 {
+		t.Fatalf("level: got %d, want 4", base.X64Level())
+	}
+
+	for level := 4; level > 0; level-- {
+		c := base
+		if !c.LimitToLevel(level) {
+			t.Fatalf("LimitToLevel(%d) returned false", level)
+		}
+		if got := c.X64Level(); got != level {
+			t.Errorf("level: got %d, want %d", got, level)
+		}
+		if c.Has(AESNI) || c.Has(AVX10) || c.AVX10Level != 0 || c.SGX.Available {
+			t.Errorf("level %d: not limited: %v", level, c.FeatureSet())
+		}
+		if level >= 3 && !c.Has(XSAVE) {
+			t.Errorf("level %d: prerequisite XSAVE removed", level)
+		}
+	}
+	c := base
+	if c.LimitToLevel(5) || c.LimitToLevel(0) || c.Features() != base.Features() {
+		t.Error("invalid level accepted")
+	}
+	var arm CPUInfo
+	arm.featureSet.setIf(true, FP, ASIMD)
+	if arm.LimitToLevel(1) || !arm.Has(ASIMD) {
+		t.Error("level applied to CPU without level")
+	}
+
+	c.LimitTo(NewFeatureSet(AVX2, SGXLC))
+	if got := c.Features().String(); got != "AVX,AVX2,OSXSAVE,SGX,SGXLC,SYSCALL,XSAVE" {
+		t.Errorf("LimitTo: got %v", got)
+	}
+	if !c.SGX.Available || !c.SGX.LaunchControl {
+		t.Error("SGX fields cleared")
+	}
+	c.Disable(SGX)
+	if c.SGX.Available || c.SGX.LaunchControl || c.Has(SGXLC) {
+		t.Error("SGX fields not cleared")
+	}
+}
+
+func TestMicroarch(t *testing.T) {
+	c := CPUInfo{VendorID: AMD, Family: 0x19, Model: 0x11, Stepping: 1}
+	m := c.Microarch()
+	if m.Codename != "Genoa" || m.Core != "Zen 4" || m.Generation != Zen4 || m.String() != "Genoa (Zen 4)" {
+		t.Errorf("got %+v", m)
+	}
+	if !m.AtLeast(Zen3) || !m.AtLeast(Zen4) || m.AtLeast(Zen5) || m.AtLeast(Haswell) || m.AtLeast(GenerationUnknown) {
+		t.Error("AtLeast mismatch")
+	}
+	if (Microarch{Generation: Gracemont}).AtLeast(Skylake) || !(Microarch{Generation: Gracemont}).AtLeast(Tremont) {
+		t.Error("AtLeast compared different lines")
+	}
+
+	// Steppings of the same model.
+	for stepping, want := range map[int]string{4: "Skylake-SP", 7: "Cascade Lake", 11: "Cooper Lake", 15: "Skylake-SP"} {
+		c := CPUInfo{VendorID: Intel, Family: 6, Model: 0x55, Stepping: stepping}
+		if got := c.Microarch(); got.Codename != want || got.Generation != Skylake {
+			t.Errorf("stepping %d: got %+v, want %s", stepping, got, want)
+		}
+	}
+	c = CPUInfo{VendorID: Intel, Family: 6, Model: 0x57}
+	if got := c.Microarch(); got.String() != "Knights Landing" || got.Core != "" || got.Generation != GenerationUnknown {
+		t.Errorf("got %+v", got)
+	}
+	c = CPUInfo{VendorID: VIA, Family: 6, Model: 0x0F}
+	if got := c.Microarch(); got.String() != "unknown" || got.Vendor != VIA {
+		t.Errorf("got %+v", got)
+	}
+	if Generation(0xffff).String() != "Generation(65535)" || ZenPlus.String() != "Zen+" {
+		t.Error("Generation.String mismatch")
+	}
+}
+
+func TestDecodeMIDR(t *testing.T) {
+	tests := []struct {
+		midr               uint64
+		vendor             Vendor
+		part, variant, rev int
+		core               string
+	}{
+		{midr: 0x413FD0C1, vendor: ARM, part: 0xD0C, variant: 3, rev: 1, core: "Neoverse N1"},
+		{midr: 0x411FD402, vendor: ARM, part: 0xD40, variant: 1, rev: 2, core: "Neoverse V1"},
+		{midr: 0x410FD034, vendor: ARM, part: 0xD03, variant: 0, rev: 4, core: "Cortex-A53"},
+		{midr: 0xC00FAC30, vendor: Ampere, part: 0xAC3, core: "AmpereOne"},
+		{midr: 0x481FD010, vendor: HiSilicon, part: 0xD01, variant: 1, core: "TaiShan v110"},
+		{midr: 0x611F0221, vendor: Apple, part: 0x022, variant: 1, rev: 1, core: "Icestorm"},
+		{midr: 0x6D0FD490, vendor: Microsoft, part: 0xD49, core: "Azure Cobalt 100"},
+		{midr: 0x701F6622, vendor: Phytium, part: 0x662, variant: 1, rev: 2, core: "FTC662"},
+		{midr: 0x410FFFF0, vendor: ARM, part: 0xFFF},
+		{midr: 0x000FD0C0, vendor: VendorUnknown, part: 0xD0C},
+	}
+	for _, test := range tests {
+		var c CPUInfo
+		decodeMIDR(&c, test.midr)
+		if c.VendorID != test.vendor || c.Family != 0xf || c.Model != test.part || c.Variant != test.variant || c.Revision != test.rev {
+			t.Errorf("%#x: got %v family %#x part %#x variant %d revision %d", test.midr, c.VendorID, c.Family, c.Model, c.Variant, c.Revision)
+		}
+		if got := c.CoreName(); got != test.core {
+			t.Errorf("%#x: got core %q, want %q", test.midr, got, test.core)
+		}
+		if test.vendor != VendorUnknown && c.VendorString == "" {
+			t.Errorf("%#x: no vendor string", test.midr)
+		}
+	}
+
+	x86 := CPUInfo{VendorID: Intel, Family: 6, Model: 0x8F}
+	if got := x86.CoreName(); got != "Golden Cove" {
+		t.Errorf("x86: got core %q", got)
+	}
+	apple := CPUInfo{VendorID: Apple, Family: 0x1b588bb3}
+	if got := apple.CoreName(); got != "Firestorm/Icestorm" {
+		t.Errorf("apple: got core %q", got)
+	}
+
+	// Variant and revision are kept by MarshalBinary, and version 1 is still read.
+	var c CPUInfo
+	decodeMIDR(&c, 0x413FD0C1)
+	b, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got CPUInfo
+	if err := got.UnmarshalBinary(b); err != nil || got.Variant != 3 || got.Revision != 1 || got.Model != 0xD0C {
+		t.Fatalf("binary round trip: %v %+v", err, got)
+	}
+
+	// Version 1 ends after the PMU fields.
+	v1 := []byte{1, ARM.code(), VendorUnknown.code(), 0, 3, 'A', 'R', 'M', 0}
+	// Cores, family, model, stepping, cache line, frequencies, cache sizes and AVX10 level.
+	for _, v := range []int64{1, 1, 1, 0xf, 0xD0C, 0, 64, 0, 0, -1, -1, -1, -1, 0} {
+		v1 = binary.AppendVarint(v1, v)
+	}
+	v1 = binary.AppendUvarint(append(v1, 1), uint64(ASIMD.Code()))
+	v1 = append(v1, 0, 0, 0, 0)             // SGX
+	v1 = append(v1, 0, 0, 0, 0, 0, 0)       // AMD memory encryption
+	v1 = append(v1, 0, 0, 0, 0, 0, 0, 0, 0) // PMU
+	got = CPUInfo{Variant: 5}
+	if err := got.UnmarshalBinary(v1); err != nil {
+		t.Fatal("version 1:", err)
+	}
+	if got.VendorID != ARM || got.VendorString != "ARM" || got.Model != 0xD0C || got.Variant != 0 || got.CacheLine != 64 ||
+		got.Cache.L1D != -1 || !got.Has(ASIMD) || got.Caches != nil {
+		t.Fatalf("version 1: %+v", got)
+	}
+	if err := got.UnmarshalBinary(v1[:len(v1)-1]); err == nil {
+		t.Fatal("version 1: no error on truncated input")
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	defer currentDispatchOverrides.Store(currentDispatchOverrides.Load())
+	setDispatchOverrides("", "")
+
+	var c CPUInfo
+	c.featureSet.setIf(true, SSE2, AVX, AVX2, BMI2)
+	d := NewDispatch[func() string]("test").
+		Register("generic", 0, nil, func() string { return "generic" }).
+		Register("avx512", 20, CombineFeatures(AVX512F, AVX512BW), func() string { return "avx512" }).
+		Register("avx2", 10, CombineFeatures(AVX2, BMI2), func() string { return "avx2" }).
+		Register("sse2", 10, CombineFeatures(SSE2), func() string { return "sse2" })
+	if got := d.SelectFor(&c)(); got != "avx2" {
+		t.Errorf("got %s, want avx2", got)
+	}
+	r := d.ReportFor(&c)
+	if r.Selected != "avx2" || len(r.Candidates) != 4 || r.Candidates[0].Name != "avx512" || r.Candidates[0].Usable() ||
+		r.Candidates[0].Missing.String() != "AVX512BW,AVX512F" || !r.Candidates[1].Usable() {
+		t.Errorf("unexpected report:\n%v", r)
+	}
+
+	setDispatchOverrides("test=generic, other=x", "")
+	if got, r := d.SelectFor(&c)(), d.ReportFor(&c); got != "generic" || r.Reason != "selected by "+EnvDispatch {
+		t.Errorf("env override: got %s, %v", got, r)
+	}
+	// The flag takes precedence, but the override is ignored when features are missing.
+	setDispatchOverrides("test=generic", "test=avx512")
+	if got, r := d.SelectFor(&c)(), d.ReportFor(&c); got != "avx2" || !strings.Contains(r.Reason, "missing [AVX512BW,AVX512F]") {
+		t.Errorf("flag override: got %s, %v", got, r)
+	}
+	setDispatchOverrides("", "")
+
+	// Select uses the current snapshot and selects again when it changes.
+	defer current.Store(current.Load())
+	publish(c)
+	if got := d.Select()(); got != "avx2" {
+		t.Errorf("got %s, want avx2", got)
+	}
+	// Changing CPU does not change the snapshot.
+	saved := CPU
+	CPU = c
+	CPU.Disable(BMI2)
+	if got := d.Select()(); got != "avx2" {
+		t.Errorf("got %s after changing CPU, want avx2", got)
+	}
+	CPU = saved
+	Update(func(c *CPUInfo) { c.LimitTo(FeatureSet{}) })
+	if got := d.Select()(); got != "generic" {
+		t.Errorf("got %s after Update, want generic", got)
+	}
+	publish(c)
+	WithDisabled(BMI2)
+	if got := d.Select()(); got != "sse2" {
+		t.Errorf("got %s after WithDisabled, want sse2", got)
+	}
+	if got := d.Report().Selected; got != "sse2" {
+		t.Errorf("report selected %s, want sse2", got)
+	}
+	publish(CPUInfo{})
+	if got := d.Select()(); got != "generic" {
+		t.Errorf("got %s, want generic", got)
+	}
+	if f := NewDispatch[func()]("empty").Select(); f != nil {
+		t.Error("empty dispatch returned function")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic on duplicate name")
+		}
+	}()
+	d.Register("avx2", 0, nil, nil)
+}
+
+func TestPartial(t *testing.T) {
+	if !CPU.Partial {
+		t.Skip("full detection")
+	}
+	if CPU.CacheLine == 0 || CPU.LogicalCores == 0 {
+		t.Errorf("cache line %d, logical cores %d", CPU.CacheLine, CPU.LogicalCores)
+	}
+	if runtime.GOARCH == "amd64" && CPU.X64Level() == 0 {
+		t.Error("no x86-64 baseline:", CPU.Features())
+	}
+	// Features guaranteed by the architecture must be detected.
+	switch runtime.GOARCH {
+	case "amd64":
+		if !CPU.Supports(SSE, SSE2) {
+			t.Error("SSE2 not detected:", CPU.Features())
+		}
+	case "arm64":
+		if !CPU.Supports(FP, ASIMD) {
+			t.Error("ASIMD not detected:", CPU.Features())
+		}
+	}
+	// Masks are applied to partial detection as well.
+	t.Setenv(EnvDisable, "SSE2,ASIMD")
+	if c := detect(); c.Has(SSE2) || c.Has(ASIMD) || !c.Partial {
+		t.Errorf("%s not applied: %v", EnvDisable, c.Features())
+	}
+	for _, id := range CPU.Features().IDs() {
+		if !CPU.Features().ContainsAll(Implies(id)) {
+			t.Errorf("%v is missing prerequisites %v", id, Implies(id).Difference(CPU.Features()))
+		}
+	}
+}
+
 func TestCombineFeatures(t *testing.T) {
 	cpu := CPU
 	for i := FeatureID(0); i < lastID; i++ {
@@ -360,3 +1275,274 @@ func BenchmarkFlags(b *testing.B) {
 		_ = a
 	})
 }
+
+func TestSysfsCaches(t *testing.T) {
+	fsys := fstest.MapFS{}
+	add := func(index, level, typ, size, ways, sets, shared string) {
+		for name, v := range map[string]string{"level": level, "type": typ, "size": size,
+			"ways_of_associativity": ways, "number_of_sets": sets, "coherency_line_size": "64",
+			"physical_line_partition": "1", "shared_cpu_list": shared} {
+			fsys["index"+index+"/"+name] = &fstest.MapFile{Data: []byte(v + "\n")}
+		}
+	}
+	add("0", "1", "Data", "48K", "12", "64", "0,8")
+	add("1", "1", "Instruction", "32K", "8", "64", "0,8")
+	add("2", "2", "Unified", "1280K", "10", "2048", "0,8")
+	add("10", "3", "Unified", "30M", "12", "40960", "0-15")
+	// Arm systems may only report the level, type and size.
+	fsys["index3/level"] = &fstest.MapFile{Data: []byte("4")}
+	fsys["index3/type"] = &fstest.MapFile{Data: []byte("Unified")}
+	fsys["index3/size"] = &fstest.MapFile{Data: []byte("64M")}
+
+	want := []CacheInfo{
+		{Level: 1, Type: CacheTypeData, Size: 48 << 10, Ways: 12, Sets: 64, LineSize: 64, Partitions: 1, SharedBy: 2},
+		{Level: 1, Type: CacheTypeInstruction, Size: 32 << 10, Ways: 8, Sets: 64, LineSize: 64, Partitions: 1, SharedBy: 2},
+		{Level: 2, Type: CacheTypeUnified, Size: 1280 << 10, Ways: 10, Sets: 2048, LineSize: 64, Partitions: 1, SharedBy: 2},
+		{Level: 4, Type: CacheTypeUnified, Size: 64 << 20},
+		{Level: 3, Type: CacheTypeUnified, Size: 30 << 20, Ways: 12, Sets: 40960, LineSize: 64, Partitions: 1, SharedBy: 16},
+	}
+	got := sysfsCaches(fsys)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+	c := CPUInfo{Cache: struct{ L1I, L1D, L2, L3 int }{-1, -1, -1, -1}}
+	c.setCaches(got)
+	if c.Cache.L1D != 48<<10 || c.Cache.L3 != 30<<20 || c.CacheLine != 64 {
+		t.Errorf("got %+v, cache line %d", c.Cache, c.CacheLine)
+	}
+}
+
+func TestTopologySystem(t *testing.T) {
+	topo := Topology{Levels: []TopologyLevel{
+		{Type: TopologyCore, Shift: 1, LogicalCPUs: 2},
+		{Type: TopologyComplex, Shift: 3, LogicalCPUs: 8},
+		{Type: TopologyPackage, Shift: 5, LogicalCPUs: 32},
+	}}
+	topo.setSystem(64, 0)
+	if topo.Packages != 2 || topo.LogicalCPUs != 64 {
+		t.Errorf("got %d packages, %d logical CPUs", topo.Packages, topo.LogicalCPUs)
+	}
+	if got := topo.PerPackage(TopologyComplex); got != 4 {
+		t.Errorf("got %d complexes per package", got)
+	}
+	if got := topo.System(TopologyCore); got != 32 {
+		t.Errorf("got %d cores", got)
+	}
+	if got, ok := topo.ID(TopologyComplex, 0x2b); !ok || got != 5 {
+		t.Errorf("got complex ID %d, %v", got, ok)
+	}
+	if got := topo.PerPackage(TopologyDie); got != 0 {
+		t.Errorf("got %d dies per package", got)
+	}
+
+	b, err := CPUInfo{Topology: topo}.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c CPUInfo
+	if err := c.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Topology, topo) {
+		t.Errorf("decoded %+v, want %+v", c.Topology, topo)
+	}
+	b, err = json.Marshal(CPUInfo{Topology: topo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = CPUInfo{}
+	if err := json.Unmarshal(b, &c); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Topology, topo) {
+		t.Errorf("JSON decoded %+v, want %+v", c.Topology, topo)
+	}
+}
+
+// leafSource returns CPUID leaves from a map, indexed by leaf and subleaf.
+type leafSource map[[2]uint32][4]uint32
+
+func (s leafSource) CPUID(op uint32) (eax, ebx, ecx, edx uint32) {
+	return s.CPUIDEX(op, 0)
+}
+
+func (s leafSource) CPUIDEX(op, op2 uint32) (eax, ebx, ecx, edx uint32) {
+	r := s[[2]uint32{op, op2}]
+	return r[0], r[1], r[2], r[3]
+}
+
+func (s leafSource) XGETBV(index uint32) (eax, edx uint32) {
+	return 0, 0
+}
+
+// repeatSource returns subleaf 0 of leaf 4 for all subleaves, like Xen.
+type repeatSource struct{ leafSource }
+
+func (s repeatSource) CPUIDEX(op, op2 uint32) (eax, ebx, ecx, edx uint32) {
+	if op == 4 {
+		op2 = 0
+	}
+	return s.leafSource.CPUIDEX(op, op2)
+}
+
+func TestCachesRepeated(t *testing.T) {
+	src := repeatSource{leafSource{
+		{0, 0}: {0x1a, 0x756e6547, 0x6c65746e, 0x49656e69}, // GenuineIntel
+		{1, 0}: {0x906a3, 0x12000800, 0, 1 << 28},
+		{4, 0}: {0x121, 0x1c0003f, 0x3f, 0}, // L1 data, 32KB
+	}}
+	c, err := DetectFrom(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CacheInfo{{Level: 1, Type: CacheTypeData, Size: 32 * 1024, LineSize: 64, Ways: 8, Partitions: 1, Sets: 64, SharedBy: 1}}
+	if !reflect.DeepEqual(c.Caches, want) {
+		t.Errorf("got %+v, want %+v", c.Caches, want)
+	}
+}
+
+func TestCachesZhaoxin(t *testing.T) {
+	src := leafSource{
+		{0, 0}:          {1, 0x68532020, 0x20206961, 0x68676e61}, // "  Shanghai  "
+		{1, 0}:          {0x7b5, 0, 0, 0},
+		{0x80000000, 0}: {0x80000006, 0, 0, 0},
+		{0x80000005, 0}: {0, 0, 0x40100140, 0x40100140}, // L1 data and instruction, 64KB, 16-way
+		{0x80000006, 0}: {0, 0, 0x04008040, 0},          // L2, 1MB, 16-way
+	}
+	c, err := DetectFrom(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.VendorID != Zhaoxin {
+		t.Fatalf("got vendor %v", c.VendorID)
+	}
+	want := []CacheInfo{
+		{Level: 1, Type: CacheTypeData, Size: 64 << 10, Ways: 16, Sets: 64, LineSize: 64, Partitions: 1, SharedBy: 1},
+		{Level: 1, Type: CacheTypeInstruction, Size: 64 << 10, Ways: 16, Sets: 64, LineSize: 64, Partitions: 1, SharedBy: 1},
+		{Level: 2, Type: CacheTypeUnified, Size: 1 << 20, Ways: 16, Sets: 1024, LineSize: 64, Partitions: 1, SharedBy: 1},
+	}
+	if !reflect.DeepEqual(c.Caches, want) {
+		t.Errorf("got %+v\nwant %+v", c.Caches, want)
+	}
+	if c.Cache.L1D != 64<<10 || c.Cache.L1I != 64<<10 || c.Cache.L2 != 1<<20 || c.Cache.L3 != -1 || c.CacheLine != 64 {
+		t.Errorf("got cache sizes %+v, line %d", c.Cache, c.CacheLine)
+	}
+}
+
+func TestLogicalCPUInfo(t *testing.T) {
+	src := leafSource{
+		{0, 0}:    {0x1a, 0x756e6547, 0x6c65746e, 0x49656e69}, // GenuineIntel
+		{1, 0}:    {0x906a3, 0x12000800, 0, 1 << 28},
+		{7, 0}:    {0, 0, 0, 1 << 15}, // HYBRID_CPU
+		{0x1a, 0}: {0x20000001, 0, 0, 0},
+	}
+	c, err := DetectFrom(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.coreType(); got != CoreTypeEfficient {
+		t.Errorf("got core type %v", got)
+	}
+	c.Topology.X2APICID = 0x13
+	c.Caches = []CacheInfo{
+		{Level: 1, Type: CacheTypeData, SharedBy: 1},
+		{Level: 2, Type: CacheTypeUnified, SharedBy: 4},
+		{Level: 3, Type: CacheTypeUnified, SharedBy: 32},
+		{Level: 4, Type: CacheTypeUnified},
+	}
+	l := logicalCPU(3, &c)
+	if want := []int{0x13, 0x4, 0, -1}; !reflect.DeepEqual(l.CacheIDs, want) {
+		t.Errorf("got cache IDs %v, want %v", l.CacheIDs, want)
+	}
+	if l.CPU != 3 || !l.Available || l.X2APICID != 0x13 || !l.Features.Contains(HYBRID_CPU) {
+		t.Errorf("got %+v", l)
+	}
+
+	cpus := []LogicalCPUInfo{l, l, {CPU: 2}}
+	cpus[1].Features = NewFeatureSet(HYBRID_CPU, AVX512F)
+	setMissingFeatures(cpus)
+	if !cpus[0].MissingFeatures.Contains(AVX512F) || cpus[1].MissingFeatures.Len() != 0 || cpus[2].MissingFeatures.Len() != 0 {
+		t.Errorf("got missing features %v, %v, %v", cpus[0].MissingFeatures, cpus[1].MissingFeatures, cpus[2].MissingFeatures)
+	}
+}
+
+func TestEnumerateCPUs(t *testing.T) {
+	cpus, err := EnumerateCPUs()
+	if err != nil {
+		if runtime.GOOS == "linux" && (runtime.GOARCH == "amd64" || runtime.GOARCH == "386") && !CPU.Partial {
+			t.Fatal(err)
+		}
+		t.Skip(err)
+	}
+	seen := make(map[uint32]int)
+	available := 0
+	for i, l := range cpus {
+		if l.CPU != i {
+			t.Errorf("CPU %d at index %d", l.CPU, i)
+		}
+		if !l.Available {
+			continue
+		}
+		available++
+		if prev, ok := seen[l.X2APICID]; ok && !CPU.Has(HYPERVISOR) {
+			t.Errorf("CPU %d and %d have x2APIC ID %d", prev, i, l.X2APICID)
+		}
+		seen[l.X2APICID] = i
+		if len(l.CacheIDs) != len(l.Caches) {
+			t.Errorf("CPU %d: %d cache IDs for %d caches", i, len(l.CacheIDs), len(l.Caches))
+		}
+	}
+	if available == 0 {
+		t.Error("no available CPUs")
+	}
+}
+
+// countingSource counts the CPUID calls made to a source.
+type countingSource struct {
+	Source
+	n int
+}
+
+func (s *countingSource) CPUID(op uint32) (eax, ebx, ecx, edx uint32) {
+	s.n++
+	return s.Source.CPUID(op)
+}
+
+func (s *countingSource) CPUIDEX(op, op2 uint32) (eax, ebx, ecx, edx uint32) {
+	s.n++
+	return s.Source.CPUIDEX(op, op2)
+}
+
+func TestLeavesLazy(t *testing.T) {
+	src := &countingSource{Source: leafSource{
+		{0, 0}:          {0xd, 0x756e6547, 0x6c65746e, 0x49656e69}, // GenuineIntel
+		{1, 0}:          {0x906a3, 0x800, 0, 1 << 28},
+		{0xd, 0}:        {0x7, 0x340, 0x340, 0}, // x87, SSE and AVX
+		{0xd, 1}:        {0x1, 0, 0, 0},
+		{0xd, 2}:        {0x100, 0x240, 0, 0},
+		{0x80000000, 0}: {0x80000001, 0, 0, 0},
+	}}
+	c, err := DetectFrom(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	detected := src.n
+	if eax, _, _, _ := c.Leaf(1, 0); eax != 0x906a3 {
+		t.Fatalf("got leaf 1 eax %x", eax)
+	}
+	read := src.n
+	if read == detected {
+		t.Fatal("leaves were read at detection")
+	}
+	var buf strings.Builder
+	if err := c.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if src.n != read {
+		t.Errorf("leaves were read again: %d calls, want %d", src.n, read)
+	}
+	// Each XSAVE subleaf is written once.
+	if got := strings.Count(buf.String(), "CPUID 0000000D:"); got != 3 {
+		t.Errorf("got %d lines of leaf 0xD, want 3:\n%s", got, buf.String())
+	}
+}
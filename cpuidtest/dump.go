@@ -0,0 +1,156 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+// Package cpuidtest provides helpers for testing code that depends on cpuid.
+//
+// CPUID dumps in the instlatx64 text format, like the ones found in
+// testdata/cpuid_data.zip, can be loaded and decoded without
+// modifying the global cpuid.CPU variable.
+package cpuidtest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/cpuid/v2"
+)
+
+// Leaf identifies a CPUID leaf and subleaf.
+type Leaf struct {
+	Op      uint32 // Value of EAX
+	Subleaf uint32 // Value of ECX
+}
+
+// Leaves contains the EAX, EBX, ECX and EDX registers for each leaf.
+// Leaves implements cpuid.Source.
+// Leaves that are not present will return all zero registers.
+type Leaves map[Leaf][4]uint32
+
+// ParseDump will parse a CPUID dump in the instlatx64 format.
+// Lines look like "CPUID 00000007: 00000002-239C27EB-98C027AC-FC1CC410 [SL 00]".
+// Only the first logical CPU in the dump is read.
+// If no "[SL xx]" marker is present, subleaves are numbered in the order they appear.
+func ParseDump(r io.Reader) (Leaves, error) {
+	leaves := make(Leaves)
+	next := make(map[uint32]uint32)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.Trim(sc.Text(), "\r\t ")
+		op, regs, sub, hasSub, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		// Only collect for first cpu
+		if op == 0 && len(leaves) > 0 {
+			break
+		}
+		if !hasSub {
+			sub = next[op]
+		}
+		next[op] = sub + 1
+		leaves[Leaf{Op: op, Subleaf: sub}] = regs
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(leaves) == 0 {
+		return nil, errors.New("cpuidtest: no CPUID leaves found")
+	}
+	return leaves, nil
+}
+
+// parseLine parses a single line of a dump.
+func parseLine(line string) (op uint32, regs [4]uint32, sub uint32, hasSub, ok bool) {
+	rest, found := strings.CutPrefix(line, "CPUID ")
+	if !found || len(rest) < 8 {
+		return
+	}
+	v, err := strconv.ParseUint(rest[:8], 16, 32)
+	if err != nil {
+		return
+	}
+	op = uint32(v)
+	rest = strings.TrimLeft(rest[8:], ": \t")
+
+	// Values are either separated by '-' or by spaces.
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return
+	}
+	vals := strings.Split(fields[0], "-")
+	if len(vals) != 4 {
+		if len(fields) < 4 {
+			return
+		}
+		vals = fields[:4]
+	}
+	for i, s := range vals {
+		v, err := strconv.ParseUint(s, 16, 32)
+		if err != nil {
+			return
+		}
+		regs[i] = uint32(v)
+	}
+	if _, sl, found := strings.Cut(rest, "[SL "); found {
+		if end := strings.IndexByte(sl, ']'); end > 0 {
+			v, err := strconv.ParseUint(sl[:end], 16, 32)
+			if err == nil {
+				sub, hasSub = uint32(v), true
+			}
+		}
+	}
+	return op, regs, sub, hasSub, true
+}
+
+// DetectDump returns the CPU information decoded from the supplied leaves.
+// The global cpuid.CPU variable is not modified.
+func DetectDump(l Leaves) cpuid.CPUInfo {
+	c, _ := cpuid.DetectFrom(l)
+	return c
+}
+
+// CPUID returns subleaf 0 of leaf op.
+func (l Leaves) CPUID(op uint32) (eax, ebx, ecx, edx uint32) {
+	return l.CPUIDEX(op, 0)
+}
+
+// CPUIDEX returns the registers of leaf op, subleaf op2.
+func (l Leaves) CPUIDEX(op, op2 uint32) (eax, ebx, ecx, edx uint32) {
+	r := l[Leaf{Op: op, Subleaf: op2}]
+	return r[0], r[1], r[2], r[3]
+}
+
+// XGETBV reports all states as enabled if OSXSAVE is set,
+// since dumps do not contain the XCR0 values.
+func (l Leaves) XGETBV(index uint32) (eax, edx uint32) {
+	_, _, ecx, _ := l.CPUID(1)
+	if ecx&(1<<27) == 0 {
+		return 0, 0
+	}
+	return math.MaxUint32, math.MaxUint32
+}
+
+// String returns the leaves in the dump format, sorted by leaf and subleaf.
+func (l Leaves) String() string {
+	keys := make([]Leaf, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Op != keys[j].Op {
+			return keys[i].Op < keys[j].Op
+		}
+		return keys[i].Subleaf < keys[j].Subleaf
+	})
+	var sb strings.Builder
+	for _, k := range keys {
+		r := l[k]
+		fmt.Fprintf(&sb, "CPUID %08X: %08X-%08X-%08X-%08X [SL %02X]\n", k.Op, r[0], r[1], r[2], r[3], k.Subleaf)
+	}
+	return sb.String()
+}
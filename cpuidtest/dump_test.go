@@ -0,0 +1,46 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuidtest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDump(t *testing.T) {
+	const dump = `CPUID Manufacturer : GenuineIntel
+CPUID Registers (CPU #1):
+CPUID 00000000: 0000000D-756E6547-6C65746E-49656E69 [GenuineIntel]
+CPUID 0000000D: 00000007-00000340-00000340-00000000 [SL 00]
+CPUID 0000000D: 00000001-00000000-00000000-00000000 [SL 01]
+CPUID 0000000D: 00000008-00000000-00000001-00000000 [SL 08]
+CPUID 80000000 : 80000004 00000000 00000000 00000000
+CPUID 00000000: 0000000D-756E6547-6C65746E-49656E69
+CPUID 00000001: 00000000-00000000-00000000-00000000
+`
+	l, err := ParseDump(strings.NewReader(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[Leaf][4]uint32{
+		{0, 0}:          {0xd, 0x756E6547, 0x6C65746E, 0x49656E69},
+		{0xd, 0}:        {7, 0x340, 0x340, 0},
+		{0xd, 1}:        {1, 0, 0, 0},
+		{0xd, 8}:        {8, 0, 1, 0},
+		{0x80000000, 0}: {0x80000004, 0, 0, 0},
+	}
+	if len(l) != len(want) {
+		t.Fatalf("got %d leaves, want %d:\n%v", len(l), len(want), l)
+	}
+	for k, v := range want {
+		if l[k] != v {
+			t.Errorf("leaf %+v: got %08x, want %08x", k, l[k], v)
+		}
+	}
+	if c := DetectDump(l); c.VendorString != "GenuineIntel" {
+		t.Errorf("unexpected vendor %q", c.VendorString)
+	}
+	if _, err := ParseDump(strings.NewReader("no cpuid here")); err == nil {
+		t.Error("expected error")
+	}
+}
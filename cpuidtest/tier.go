@@ -0,0 +1,84 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuidtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/klauspost/cpuid/v2"
+)
+
+// Tier is a set of features to run a test with.
+// The zero value runs with all detected features.
+type Tier struct {
+	Name string
+	// Level limits features to an x86-64 microarchitecture level, if above 0.
+	// The tier is skipped if the CPU is below the level.
+	Level int
+	// Disable contains features to disable.
+	// Features requiring them are also disabled.
+	Disable []cpuid.FeatureID
+}
+
+// Levels returns tiers for the x86-64 microarchitecture levels 1 to 4.
+func Levels() []Tier {
+	return []Tier{
+		{Name: "x86-64-v1", Level: 1},
+		{Name: "x86-64-v2", Level: 2},
+		{Name: "x86-64-v3", Level: 3},
+		{Name: "x86-64-v4", Level: 4},
+	}
+}
+
+// Without returns a tier with the features disabled, named like "no-AVX512F-BMI2".
+func Without(ids ...cpuid.FeatureID) Tier {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = id.String()
+	}
+	return Tier{Name: "no-" + strings.Join(names, "-"), Disable: ids}
+}
+
+// TB is implemented by *testing.T and *testing.B.
+type TB[T any] interface {
+	testing.TB
+	Run(name string, f func(T)) bool
+}
+
+// ForEachTier runs f as a subtest or sub-benchmark for each tier.
+// While f runs, the snapshot returned by cpuid.Current is masked to the features of the tier,
+// so cpuid.Dispatch selects implementations for the tier.
+// The global cpuid.CPU is set to the same masked copy.
+// Both are restored when the subtest or sub-benchmark is done.
+// Tiers with a Level above the level of the CPU are skipped.
+//
+// Since global state is modified, the test must not run in parallel with
+// other tests using cpuid.CPU, cpuid.Current or cpuid.Dispatch.
+func ForEachTier[T TB[T]](t T, tiers []Tier, f func(t T)) {
+	t.Helper()
+	for _, tier := range tiers {
+		name := tier.Name
+		if name == "" {
+			name = "native"
+		}
+		t.Run(name, func(t T) {
+			saved, snapshot := cpuid.CPU, cpuid.Current()
+			t.Cleanup(func() {
+				cpuid.CPU = saved
+				cpuid.Update(func(c *cpuid.CPUInfo) { *c = *snapshot })
+			})
+			masked := *snapshot
+			if tier.Level > 0 {
+				if level := masked.X64Level(); level < tier.Level {
+					t.Skipf("CPU is x86-64 level %d", level)
+				}
+				masked.LimitToLevel(tier.Level)
+			}
+			masked.Disable(tier.Disable...)
+			cpuid.CPU = masked
+			cpuid.Update(func(c *cpuid.CPUInfo) { *c = masked })
+			f(t)
+		})
+	}
+}
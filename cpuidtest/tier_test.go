@@ -0,0 +1,68 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuidtest
+
+import (
+	"testing"
+
+	"github.com/klauspost/cpuid/v2"
+)
+
+func TestForEachTier(t *testing.T) {
+	before := cpuid.CPU.Features()
+	var ran []string
+	tiers := append(Levels(), Without(cpuid.SSE2), Tier{})
+	ForEachTier(t, tiers, func(t *testing.T) {
+		ran = append(ran, t.Name())
+		switch t.Name() {
+		case "TestForEachTier/no-SSE2":
+			if cpuid.CPU.Has(cpuid.SSE2) || cpuid.CPU.Has(cpuid.SSE3) {
+				t.Error("SSE2 not disabled:", cpuid.CPU.Features())
+			}
+		case "TestForEachTier/native":
+			if cpuid.CPU.Features() != before {
+				t.Error("native tier masked")
+			}
+		default:
+			if level := cpuid.CPU.X64Level(); len(ran) != level {
+				t.Errorf("got level %d", level)
+			}
+		}
+	})
+	if cpuid.CPU.Features() != before || cpuid.Current().Features() != before {
+		t.Error("CPU not restored")
+	}
+	if len(ran) != cpuid.CPU.X64Level()+2 {
+		t.Errorf("ran %v", ran)
+	}
+}
+
+func TestForEachTierDispatch(t *testing.T) {
+	d := cpuid.NewDispatch[func() string]("tier").
+		Register("generic", 0, nil, func() string { return "generic" }).
+		Register("avx2", 10, cpuid.CombineFeatures(cpuid.AVX2), func() string { return "avx2" })
+	native := d.Select()()
+	ForEachTier(t, []Tier{Without(cpuid.AVX2), {}}, func(t *testing.T) {
+		want := "generic"
+		if cpuid.Current().Has(cpuid.AVX2) {
+			want = "avx2"
+		}
+		if got := d.Select()(); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+		if t.Name() == "TestForEachTierDispatch/no-AVX2" && (cpuid.Current().Has(cpuid.AVX2) || cpuid.CPU.Has(cpuid.AVX2)) {
+			t.Error("AVX2 not disabled")
+		}
+	})
+	if got := d.Select()(); got != native {
+		t.Errorf("got %s after ForEachTier, want %s", got, native)
+	}
+}
+
+func BenchmarkForEachTier(b *testing.B) {
+	ForEachTier(b, []Tier{Without(cpuid.AVX2), {}}, func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = cpuid.CPU.Has(cpuid.AVX2)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+// featureDeps lists the features each feature directly requires.
+// Requirements are transitive, so only the closest prerequisites are listed.
+var featureDeps = [lastID][]FeatureID{
+	// x86 SSE chain
+	SSE:   {FXSR},
+	SSE2:  {SSE},
+	SSE3:  {SSE2},
+	SSSE3: {SSE3},
+	SSE4:  {SSSE3},
+	SSE42: {SSE4},
+	SSE4A: {SSE3},
+	AESNI: {SSE2},
+	CLMUL: {SSE2},
+	GFNI:  {SSE2},
+	SHA:   {SSE2},
+
+	// MMX and 3DNow!
+	MMXEXT:      {MMX},
+	AMD3DNOW:    {MMX},
+	AMD3DNOWEXT: {AMD3DNOW},
+
+	// XSAVE
+	OSXSAVE:  {XSAVE},
+	XSAVEOPT: {XSAVE},
+	XSAVEC:   {XSAVE},
+	XSAVES:   {XSAVE},
+	XGETBV1:  {XSAVE},
+
+	// AVX
+	AVX:          {OSXSAVE},
+	AVX2:         {AVX},
+	AVXSLOW:      {AVX},
+	FMA3:         {AVX},
+	FMA4:         {AVX},
+	XOP:          {AVX},
+	F16C:         {AVX},
+	VAES:         {AVX, AESNI},
+	VPCLMULQDQ:   {AVX, CLMUL},
+	SM3_X86:      {AVX},
+	SM4_X86:      {AVX},
+	AVXVNNI:      {AVX2},
+	AVXIFMA:      {AVX2},
+	AVXNECONVERT: {AVX2},
+	AVXVNNIINT8:  {AVX2},
+	AVXVNNIINT16: {AVX2},
+
+	// AVX-512
+	AVX512F:            {AVX2},
+	AVX512CD:           {AVX512F},
+	AVX512BW:           {AVX512F},
+	AVX512DQ:           {AVX512F},
+	AVX512VL:           {AVX512F},
+	AVX512ER:           {AVX512F},
+	AVX512PF:           {AVX512F},
+	AVX512IFMA:         {AVX512F},
+	AVX512VPOPCNTDQ:    {AVX512F},
+	AVX512BMM:          {AVX512F},
+	AVX512VBMI:         {AVX512BW},
+	AVX512VBMI2:        {AVX512BW},
+	AVX512BITALG:       {AVX512BW},
+	AVX512FP16:         {AVX512BW},
+	AVX512VNNI:         {AVX512F},
+	AVX512BF16:         {AVX512F},
+	AVX512VP2INTERSECT: {AVX512F},
+
+	// AVX10
+	AVX10:     {AVX2},
+	AVX10_128: {AVX10},
+	AVX10_256: {AVX10},
+	AVX10_512: {AVX10},
+
+	// AMX
+	AMXTILE:      {OSXSAVE},
+	AMXBF16:      {AMXTILE},
+	AMXFP16:      {AMXTILE},
+	AMXINT8:      {AMXTILE},
+	AMXFP8:       {AMXTILE},
+	AMXTF32:      {AMXTILE},
+	AMXCOMPLEX:   {AMXTILE},
+	AMXTRANSPOSE: {AMXTILE},
+
+	// Other x86
+	KEYLOCKERW: {KEYLOCKER},
+	SGXLC:      {SGX},
+	SGXPQC:     {SGX},
+
+	// ARM
+	ASIMD:    {FP},
+	FPHP:     {FP},
+	JSCVT:    {FP},
+	ASIMDHP:  {ASIMD, FPHP},
+	ASIMDDP:  {ASIMD},
+	ASIMDRDM: {ASIMD},
+	FCMA:     {ASIMD},
+	FHM:      {ASIMDHP},
+	AESARM:   {ASIMD},
+	PMULL:    {AESARM},
+	SHA1:     {ASIMD},
+	SHA2:     {ASIMD},
+	SHA512:   {SHA2},
+	SHA3:     {ASIMD},
+	SM3:      {ASIMD},
+	SM4:      {ASIMD},
+	SVE:      {ASIMD},
+}
+
+// featureRequires and featureDependents contain the transitive closure of featureDeps.
+// featureRequires[f] contains all features f requires,
+// and featureDependents[f] contains all features that require f.
+var featureRequires, featureDependents = func() (req, dep [lastID]flagSet) {
+	var visit func(f FeatureID, into *flagSet)
+	visit = func(f FeatureID, into *flagSet) {
+		for _, d := range featureDeps[f] {
+			if !into.inSet(d) {
+				into.set(d)
+				visit(d, into)
+			}
+		}
+	}
+	for f := firstID + 1; f < lastID; f++ {
+		visit(f, &req[f])
+		for g := firstID + 1; g < lastID; g++ {
+			if req[f].inSet(g) {
+				dep[g].set(f)
+			}
+		}
+	}
+	return req, dep
+}()
+
+// DisableUnsupported disables features that were detected without all their prerequisites
+// and returns the disabled features.
+// Some CPUs report such features, for example F16C without AVX,
+// but they cannot be used. Detection reports them as they are.
+// Features depending on the disabled features are also disabled.
+func (c *CPUInfo) DisableUnsupported() FeatureSet {
+	before := c.featureSet
+	var ids []FeatureID
+	for f := firstID + 1; f < lastID; f++ {
+		if before.inSet(f) && !before.hasSet(featureRequires[f]) {
+			ids = append(ids, f)
+		}
+	}
+	c.Disable(ids...)
+	return FeatureSet{s: before}.Difference(c.Features())
+}
+
+// Implies returns all features that are required for f to be usable.
+// A CPU having f will also have all the returned features.
+// f itself is not included.
+func Implies(f FeatureID) FeatureSet {
+	if f <= firstID || f >= lastID {
+		return FeatureSet{}
+	}
+	return FeatureSet{s: featureRequires[f]}
+}
+
+// MissingPrerequisites returns the features required by ids
+// that are not enabled on c and not included in ids.
+// Enable will refuse to enable features while prerequisites are missing.
+func (c CPUInfo) MissingPrerequisites(ids ...FeatureID) FeatureSet {
+	have := c.featureSet
+	var need flagSet
+	for _, id := range ids {
+		if id > firstID && id < lastID {
+			have.set(id)
+			need.or(featureRequires[id])
+		}
+	}
+	for i := range need {
+		need[i] &^= have[i]
+	}
+	return FeatureSet{s: need}
+}
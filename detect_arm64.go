@@ -28,88 +28,7 @@ func addInfo(c *CPUInfo, safe bool) {
 	if safe && !c.Has(ARMCPUID) && runtime.GOOS != "freebsd" {
 		return
 	}
-	midr := getMidr()
-
-	// MIDR_EL1 - Main ID Register
-	// https://developer.arm.com/docs/ddi0595/h/aarch64-system-registers/midr_el1
-	//  x--------------------------------------------------x
-	//  | Name                         |  bits   | visible |
-	//  |--------------------------------------------------|
-	//  | Implementer                  | [31-24] |    y    |
-	//  |--------------------------------------------------|
-	//  | Variant                      | [23-20] |    y    |
-	//  |--------------------------------------------------|
-	//  | Architecture                 | [19-16] |    y    |
-	//  |--------------------------------------------------|
-	//  | PartNum                      | [15-4]  |    y    |
-	//  |--------------------------------------------------|
-	//  | Revision                     | [3-0]   |    y    |
-	//  x--------------------------------------------------x
-
-	switch (midr >> 24) & 0xff {
-	case 0xC0:
-		c.VendorString = "Ampere Computing"
-		c.VendorID = Ampere
-	case 0x41:
-		c.VendorString = "Arm Limited"
-		c.VendorID = ARM
-	case 0x42:
-		c.VendorString = "Broadcom Corporation"
-		c.VendorID = Broadcom
-	case 0x43:
-		c.VendorString = "Cavium Inc"
-		c.VendorID = Cavium
-	case 0x44:
-		c.VendorString = "Digital Equipment Corporation"
-		c.VendorID = DEC
-	case 0x46:
-		c.VendorString = "Fujitsu Ltd"
-		c.VendorID = Fujitsu
-	case 0x49:
-		c.VendorString = "Infineon Technologies AG"
-		c.VendorID = Infineon
-	case 0x4D:
-		c.VendorString = "Motorola or Freescale Semiconductor Inc"
-		c.VendorID = Motorola
-	case 0x4E:
-		c.VendorString = "NVIDIA Corporation"
-		c.VendorID = NVIDIA
-	case 0x50:
-		c.VendorString = "Applied Micro Circuits Corporation"
-		c.VendorID = AMCC
-	case 0x51:
-		c.VendorString = "Qualcomm Inc"
-		c.VendorID = Qualcomm
-	case 0x56:
-		c.VendorString = "Marvell International Ltd"
-		c.VendorID = Marvell
-	case 0x69:
-		c.VendorString = "Intel Corporation"
-		c.VendorID = Intel
-	}
-
-	// Lower 4 bits: Architecture
-	// Architecture	Meaning
-	// 0b0001		Armv4.
-	// 0b0010		Armv4T.
-	// 0b0011		Armv5 (obsolete).
-	// 0b0100		Armv5T.
-	// 0b0101		Armv5TE.
-	// 0b0110		Armv5TEJ.
-	// 0b0111		Armv6.
-	// 0b1111		Architectural features are individually identified in the ID_* registers, see 'ID registers'.
-	// Upper 4 bit: Variant
-	// An IMPLEMENTATION DEFINED variant number.
-	// Typically, this field is used to distinguish between different product variants, or major revisions of a product.
-	c.Family = int(midr>>16) & 0xff
-
-	// PartNum, bits [15:4]
-	// An IMPLEMENTATION DEFINED primary part number for the device.
-	// On processors implemented by Arm, if the top four bits of the primary
-	// part number are 0x0 or 0x7, the variant and architecture are encoded differently.
-	// Revision, bits [3:0]
-	// An IMPLEMENTATION DEFINED revision number for the device.
-	c.Model = int(midr) & 0xffff
+	decodeMIDR(c, getMidr())
 
 	procFeatures := getProcFeatures()
 
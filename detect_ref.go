@@ -5,6 +5,13 @@
 
 package cpuid
 
+import (
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/cpu"
+)
+
 func initCPU() {
 	cpuid = func(uint32) (a, b, c, d uint32) { return 0, 0, 0, 0 }
 	cpuidex = func(x, y uint32) (a, b, c, d uint32) { return 0, 0, 0, 0 }
@@ -13,5 +20,24 @@ func initCPU() {
 
 }
 
-func addInfo(info *CPUInfo, safe bool) {}
+// addInfo fills features from golang.org/x/sys/cpu, which needs no assembly in this package.
+// Only features known by golang.org/x/sys/cpu are detected, so the result is marked as partial.
+func addInfo(info *CPUInfo, safe bool) {
+	info.Partial = true
+	info.CacheLine = int(unsafe.Sizeof(cpu.CacheLinePad{}))
+	info.LogicalCores = runtime.NumCPU()
+	if runtime.GOARCH == "amd64" {
+		// Guaranteed by the architecture.
+		info.featureSet.or(*level1Features)
+		info.featureSet.set(SYSCALL)
+	}
+	for _, f := range runtimeFeatures(runtime.GOARCH) {
+		if *f.v {
+			info.featureSet.set(f.id)
+			// Features are only reported as usable if their prerequisites are present.
+			info.featureSet.or(featureRequires[f.id])
+		}
+	}
+}
+
 func getVectorLength() (vl, pl uint64) { return 0, 0 }
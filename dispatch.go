@@ -0,0 +1,266 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Dispatch selects the best implementation of a function for the CPU.
+// Implementations are registered with the features they require and a priority.
+// Select returns the usable implementation with the highest priority.
+// If priorities are equal, the first registered implementation is preferred.
+// Register an implementation without required features as a fallback.
+//
+// The selection can be overridden with the EnvDispatch environment variable
+// or the cpu.dispatch flag, for example CPUID_DISPATCH=crc32=generic.
+// Overrides of implementations that require features not present are ignored.
+//
+// Select uses the snapshot returned by Current, which includes features
+// disabled by GODEBUG, environment variables and flags.
+// Changes made directly to CPU, for example with CPU.Disable, are not seen.
+// Use WithDisabled or Update to change the snapshot.
+// The selection is cached, and is done again when the features of the snapshot change,
+// for example after Detect, Redetect, WithDisabled or Update.
+// Select is safe for concurrent use.
+type Dispatch[F any] struct {
+	name     string
+	mu       sync.Mutex
+	impls    []dispatchImpl[F] // Sorted by priority.
+	selected atomic.Pointer[dispatchSelection[F]]
+}
+
+// dispatchImpl is a registered implementation.
+type dispatchImpl[F any] struct {
+	name     string
+	priority int
+	requires flagSet
+	fn       F
+}
+
+// dispatchSelection is a cached selection.
+type dispatchSelection[F any] struct {
+	features  flagSet
+	overrides *dispatchOverrides
+	fn        F
+}
+
+// DispatchReport describes the selection of a Dispatch.
+type DispatchReport struct {
+	Name       string              // Name of the Dispatch
+	Selected   string              // Name of the selected implementation, empty if none is usable
+	Reason     string              // Why the implementation was selected
+	Candidates []DispatchCandidate // Registered implementations, by priority
+}
+
+// DispatchCandidate is an implementation of a Dispatch.
+type DispatchCandidate struct {
+	Name     string
+	Priority int
+	Requires FeatureSet // Features required by the implementation
+	Missing  FeatureSet // Required features not present on the CPU
+}
+
+// Usable returns whether the CPU has all features required by the candidate.
+func (d DispatchCandidate) Usable() bool {
+	return d.Missing.Len() == 0
+}
+
+// String returns the report as multiple lines.
+func (r DispatchReport) String() string {
+	var sb strings.Builder
+	selected := r.Selected
+	if selected == "" {
+		selected = "none"
+	}
+	fmt.Fprintf(&sb, "%s: %s (%s)\n", r.Name, selected, r.Reason)
+	for _, c := range r.Candidates {
+		fmt.Fprintf(&sb, "  %s: priority %d, requires [%v]", c.Name, c.Priority, c.Requires)
+		if !c.Usable() {
+			fmt.Fprintf(&sb, ", missing [%v]", c.Missing)
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// NewDispatch returns a Dispatch with the name used for overrides and reports.
+func NewDispatch[F any](name string) *Dispatch[F] {
+	return &Dispatch[F]{name: name}
+}
+
+// Name returns the name of the Dispatch.
+func (d *Dispatch[F]) Name() string {
+	return d.name
+}
+
+// Register adds an implementation that requires the features.
+// requires may be nil for an implementation that runs on all CPUs.
+// Register will panic if the name is already registered.
+// d is returned, so calls can be chained.
+func (d *Dispatch[F]) Register(name string, priority int, requires Features, fn F) *Dispatch[F] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, impl := range d.impls {
+		if impl.name == name {
+			panic(fmt.Sprintf("cpuid: dispatch %s: %s registered twice", d.name, name))
+		}
+	}
+	impl := dispatchImpl[F]{name: name, priority: priority, fn: fn}
+	if requires != nil {
+		impl.requires = *requires
+	}
+	d.impls = append(d.impls, impl)
+	sort.SliceStable(d.impls, func(i, j int) bool { return d.impls[i].priority > d.impls[j].priority })
+	d.selected.Store(nil)
+	return d
+}
+
+// Select returns the best implementation for the snapshot returned by Current.
+// The zero value of F is returned if no implementation is usable.
+func (d *Dispatch[F]) Select() F {
+	features, overrides := current.Load().featureSet, currentDispatchOverrides.Load()
+	if s := d.selected.Load(); s != nil && s.features == features && s.overrides == overrides {
+		return s.fn
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s := &dispatchSelection[F]{features: features, overrides: overrides}
+	if i, _ := d.choose(features, overrides); i >= 0 {
+		s.fn = d.impls[i].fn
+	}
+	d.selected.Store(s)
+	return s.fn
+}
+
+// SelectFor returns the best implementation for c.
+// Overrides are applied, but the selection is not cached.
+func (d *Dispatch[F]) SelectFor(c *CPUInfo) F {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var fn F
+	if i, _ := d.choose(c.featureSet, currentDispatchOverrides.Load()); i >= 0 {
+		fn = d.impls[i].fn
+	}
+	return fn
+}
+
+// Report returns which implementation is selected for the snapshot returned by Current and why.
+func (d *Dispatch[F]) Report() DispatchReport {
+	return d.ReportFor(current.Load())
+}
+
+// ReportFor returns which implementation is selected for c and why.
+func (d *Dispatch[F]) ReportFor(c *CPUInfo) DispatchReport {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	i, reason := d.choose(c.featureSet, currentDispatchOverrides.Load())
+	r := DispatchReport{Name: d.name, Reason: reason}
+	if i >= 0 {
+		r.Selected = d.impls[i].name
+	}
+	for _, impl := range d.impls {
+		requires := FeatureSet{s: impl.requires}
+		r.Candidates = append(r.Candidates, DispatchCandidate{
+			Name:     impl.name,
+			Priority: impl.priority,
+			Requires: requires,
+			Missing:  requires.Difference(c.Features()),
+		})
+	}
+	return r
+}
+
+// choose returns the index of the implementation to use with the features and the reason.
+// -1 is returned if none is usable. d.mu must be held.
+func (d *Dispatch[F]) choose(features flagSet, overrides *dispatchOverrides) (int, string) {
+	var reason string
+	if o, ok := overrides.lookup(d.name); ok {
+		i := d.index(o.impl)
+		switch {
+		case i < 0:
+			reason = fmt.Sprintf("%s: unknown implementation %q ignored; ", o.source, o.impl)
+		case !features.hasSet(d.impls[i].requires):
+			missing := FeatureSet{s: d.impls[i].requires}.Difference(FeatureSet{s: features})
+			reason = fmt.Sprintf("%s: %s ignored, missing [%v]; ", o.source, o.impl, missing)
+		default:
+			return i, "selected by " + o.source
+		}
+	}
+	for i, impl := range d.impls {
+		if features.hasSet(impl.requires) {
+			return i, reason + "highest priority usable implementation"
+		}
+	}
+	return -1, reason + "no usable implementation"
+}
+
+// index returns the index of the implementation with the name, or -1.
+func (d *Dispatch[F]) index(name string) int {
+	for i, impl := range d.impls {
+		if impl.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// dispatchOverride is an implementation selected by the user.
+type dispatchOverride struct {
+	impl   string
+	source string // The environment variable or flag
+}
+
+// dispatchOverrides contains overrides by Dispatch name.
+type dispatchOverrides map[string]dispatchOverride
+
+// currentDispatchOverrides contains the overrides applied at the last detection.
+// It is replaced on each detection, which also makes Dispatch select again.
+var currentDispatchOverrides atomic.Pointer[dispatchOverrides]
+
+func (o *dispatchOverrides) lookup(name string) (dispatchOverride, bool) {
+	if o == nil {
+		return dispatchOverride{}, false
+	}
+	v, ok := (*o)[name]
+	return v, ok
+}
+
+// parseDispatchOverrides adds overrides from a comma separated list of dispatch=implementation to o.
+// Invalid entries are reported with the source and skipped.
+func parseDispatchOverrides(o dispatchOverrides, source, v string, warn func(source, format string, args ...interface{})) {
+	for _, field := range strings.Split(v, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		name, impl, ok := strings.Cut(field, "=")
+		name, impl = strings.TrimSpace(name), strings.TrimSpace(impl)
+		if !ok || name == "" || impl == "" {
+			warn(source, "invalid dispatch override %q, must be dispatch=implementation", field)
+			continue
+		}
+		o[name] = dispatchOverride{impl: impl, source: source}
+	}
+}
+
+// applyDispatchOverrides publishes the overrides of EnvDispatch and the cpu.dispatch flag.
+// The flag takes precedence.
+func applyDispatchOverrides() {
+	setDispatchOverrides(os.Getenv(EnvDispatch), cpuFlags.dispatch)
+}
+
+// setDispatchOverrides publishes the overrides of the environment variable and flag values.
+func setDispatchOverrides(env, flagValue string) {
+	o := dispatchOverrides{}
+	parseDispatchOverrides(o, EnvDispatch, env, envWarn)
+	parseDispatchOverrides(o, "-cpu.dispatch", flagValue, func(_, format string, args ...interface{}) {
+		flagWarn("cpu.dispatch", format, args...)
+	})
+	currentDispatchOverrides.Store(&o)
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import "errors"
+
+// CoreType is the type of a core in a CPU with more than one type of core.
+type CoreType uint8
+
+// Core types.
+const (
+	CoreTypeUnknown     CoreType = iota // Not reported, or all cores are of the same type
+	CoreTypePerformance                 // Intel Core, or AMD performance core
+	CoreTypeEfficient                   // Intel Atom, or AMD efficiency core
+)
+
+// String returns the name of the core type.
+func (t CoreType) String() string {
+	switch t {
+	case CoreTypePerformance:
+		return "Performance"
+	case CoreTypeEfficient:
+		return "Efficient"
+	}
+	return "Unknown"
+}
+
+// LogicalCPUInfo is the information of a logical CPU returned by EnumerateCPUs.
+type LogicalCPUInfo struct {
+	CPU int // OS CPU number
+	// Available is set if the CPU could be used.
+	// CPUs that are offline or not allowed for the process are not available,
+	// and have no other fields set.
+	Available bool
+	X2APICID  uint32
+	CoreType  CoreType
+	Topology  Topology    // Topology reported by the CPU. LogicalCPUs and Packages are not set
+	Caches    []CacheInfo // Caches reported by the CPU
+	// CacheIDs contains the instance ID of each cache in Caches, or -1 if unknown.
+	// Logical CPUs sharing a cache have the same ID for it.
+	CacheIDs []int
+	Features FeatureSet // Features detected on the CPU, without flags or other settings applied
+	// MissingFeatures contains the features detected on other available CPUs, but not on this one.
+	MissingFeatures FeatureSet
+}
+
+// ID returns the ID of the topology domain containing the CPU.
+// IDs are unique in the system.
+func (l LogicalCPUInfo) ID(typ TopologyType) (uint32, bool) {
+	if !l.Available {
+		return 0, false
+	}
+	return l.Topology.ID(typ, l.X2APICID)
+}
+
+// errNoCPUID is returned by EnumerateCPUs when CPUID cannot be executed.
+var errNoCPUID = errors.New("cpuid: CPUID is not available")
+
+// logicalCPU returns the information of the logical CPU c was detected on.
+func logicalCPU(cpu int, c *CPUInfo) LogicalCPUInfo {
+	l := LogicalCPUInfo{
+		CPU:       cpu,
+		Available: true,
+		X2APICID:  c.Topology.X2APICID,
+		CoreType:  c.coreType(),
+		Topology:  c.Topology,
+		Caches:    c.Caches,
+		Features:  c.Features(),
+	}
+	for _, ci := range c.Caches {
+		id := -1
+		if ci.SharedBy > 0 {
+			id = int(l.X2APICID >> shiftFor(ci.SharedBy))
+		}
+		l.CacheIDs = append(l.CacheIDs, id)
+	}
+	return l
+}
+
+// setMissingFeatures sets the features that are not detected on all available CPUs.
+func setMissingFeatures(cpus []LogicalCPUInfo) {
+	var all FeatureSet
+	for _, l := range cpus {
+		all = all.Union(l.Features)
+	}
+	for i, l := range cpus {
+		if l.Available {
+			cpus[i].MissingFeatures = all.Difference(l.Features)
+		}
+	}
+}
+
+// coreType returns the type of the core detection ran on.
+func (c *CPUInfo) coreType() CoreType {
+	if c.src == nil {
+		return CoreTypeUnknown
+	}
+	switch c.VendorID {
+	case Intel:
+		if c.maxFunc < 0x1a || !c.Has(HYBRID_CPU) {
+			return CoreTypeUnknown
+		}
+		eax, _, _, _ := c.src.CPUID(0x1a)
+		switch eax >> 24 {
+		case 0x20:
+			return CoreTypeEfficient
+		case 0x40:
+			return CoreTypePerformance
+		}
+	case AMD:
+		if c.maxExFunc < 0x80000026 {
+			return CoreTypeUnknown
+		}
+		// The core type is only valid if the cores are heterogeneous.
+		eax, ebx, _, _ := c.src.CPUIDEX(0x80000026, 0)
+		if eax&(1<<30) == 0 {
+			return CoreTypeUnknown
+		}
+		switch ebx >> 28 {
+		case 0:
+			return CoreTypePerformance
+		case 1:
+			return CoreTypeEfficient
+		}
+	}
+	return CoreTypeUnknown
+}
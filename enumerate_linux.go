@@ -0,0 +1,92 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// maxAffinityCPUs is the number of CPUs that can be represented by unix.CPUSet.
+const maxAffinityCPUs = int(unsafe.Sizeof(unix.CPUSet{})) * 8
+
+// EnumerateCPUs detects each logical CPU of the system,
+// by pinning a thread to each CPU in turn and reading CPUID on it.
+// The result is indexed by the OS CPU number.
+// The affinity of the thread is restored afterwards.
+// An error is returned if CPUID is not available, if the affinity cannot be changed,
+// or if the system has CPU numbers that cannot be represented by unix.CPUSet (1024 and up).
+func EnumerateCPUs() ([]LogicalCPUInfo, error) {
+	if _, err := DetectFrom(nativeSource{}); err != nil {
+		return nil, errNoCPUID
+	}
+	n := possibleCPUs()
+	if n > maxAffinityCPUs {
+		return nil, fmt.Errorf("cpuid: %d possible CPUs, only %d are supported", n, maxAffinityCPUs)
+	}
+	type result struct {
+		cpus []LogicalCPUInfo
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		// The thread is only unlocked if the affinity was restored.
+		// Otherwise it exits with the goroutine.
+		runtime.LockOSThread()
+		var orig unix.CPUSet
+		if err := unix.SchedGetaffinity(0, &orig); err != nil {
+			runtime.UnlockOSThread()
+			done <- result{err: fmt.Errorf("cpuid: reading affinity: %w", err)}
+			return
+		}
+		cpus := make([]LogicalCPUInfo, n)
+		var setErr error
+		pinned := 0
+		for i := range cpus {
+			cpus[i].CPU = i
+			var set unix.CPUSet
+			set.Set(i)
+			if err := unix.SchedSetaffinity(0, &set); err != nil {
+				setErr = err
+				continue
+			}
+			pinned++
+			c, err := DetectFrom(nativeSource{})
+			if err != nil {
+				continue
+			}
+			cpus[i] = logicalCPU(i, &c)
+		}
+		if err := unix.SchedSetaffinity(0, &orig); err != nil {
+			done <- result{err: fmt.Errorf("cpuid: restoring affinity: %w", err)}
+			return
+		}
+		runtime.UnlockOSThread()
+		if pinned == 0 {
+			done <- result{err: fmt.Errorf("cpuid: setting affinity: %w", setErr)}
+			return
+		}
+		done <- result{cpus: cpus}
+	}()
+	r := <-done
+	if r.err != nil {
+		return nil, r.err
+	}
+	setMissingFeatures(r.cpus)
+	return r.cpus, nil
+}
+
+// possibleCPUs returns the number of CPU numbers the OS may use.
+func possibleCPUs() int {
+	if b, err := os.ReadFile("/sys/devices/system/cpu/possible"); err == nil {
+		if cpus := cpuList(strings.TrimSpace(string(b))); len(cpus) > 0 {
+			return cpus[len(cpus)-1] + 1
+		}
+	}
+	return runtime.NumCPU()
+}
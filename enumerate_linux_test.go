@@ -0,0 +1,31 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import (
+	"runtime"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestEnumerateCPUsAffinity(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	var before, after unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &before); err != nil {
+		t.Skip(err)
+	}
+	if _, err := EnumerateCPUs(); err != nil {
+		t.Skip(err)
+	}
+	if err := unix.SchedGetaffinity(0, &after); err != nil {
+		t.Fatal(err)
+	}
+	if before != after {
+		t.Errorf("affinity changed from %v to %v", before, after)
+	}
+	if maxAffinityCPUs != 1024 {
+		t.Errorf("got %d affinity CPUs", maxAffinityCPUs)
+	}
+}
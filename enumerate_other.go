@@ -0,0 +1,14 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+//go:build !linux
+// +build !linux
+
+package cpuid
+
+import "errors"
+
+// EnumerateCPUs detects each logical CPU of the system.
+// It is only supported on Linux, and returns an error on other platforms.
+func EnumerateCPUs() ([]LogicalCPUInfo, error) {
+	return nil, errors.New("cpuid: EnumerateCPUs is only supported on Linux")
+}
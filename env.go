@@ -0,0 +1,70 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variables applied when the CPU is detected,
+// including the detection done when the package is initialized.
+const (
+	// EnvDisable is a comma separated list of features to disable.
+	// Features depending on them are also disabled.
+	EnvDisable = "CPUID_DISABLE"
+	// EnvEnable is a comma separated list of features to enable, even if not detected.
+	// Features are only enabled if their prerequisites are present.
+	EnvEnable = "CPUID_ENABLE"
+	// EnvMaxLevel limits x86 features to those of an x86-64 microarchitecture level from 1 to 4.
+	EnvMaxLevel = "CPUID_MAXLEVEL"
+	// EnvDispatch selects implementations of Dispatch by name,
+	// as a comma separated list of dispatch=implementation.
+	EnvDispatch = "CPUID_DISPATCH"
+)
+
+// applyEnv applies the masks of EnvMaxLevel, EnvDisable and EnvEnable to c, in that order.
+// Invalid values are reported on stderr and ignored.
+func applyEnv(c *CPUInfo) {
+	if v := strings.TrimSpace(os.Getenv(EnvMaxLevel)); v != "" {
+		level, err := strconv.Atoi(v)
+		if err != nil || levelFeatures(level) == nil {
+			envWarn(EnvMaxLevel, "invalid level %q", v)
+		} else {
+			c.LimitToLevel(level)
+		}
+	}
+	if v := os.Getenv(EnvDisable); v != "" {
+		c.Disable(parseFeatureList(EnvDisable, v)...)
+	}
+	if v := os.Getenv(EnvEnable); v != "" {
+		ids := parseFeatureList(EnvEnable, v)
+		if !c.Enable(ids...) {
+			envWarn(EnvEnable, "missing prerequisites %v", c.MissingPrerequisites(ids...))
+		}
+	}
+}
+
+// parseFeatureList parses a comma separated list of features.
+// Unknown features are reported with the source of the list and skipped.
+func parseFeatureList(source, v string) []FeatureID {
+	var ids []FeatureID
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if id := ParseFeature(name); id != UNKNOWN {
+			ids = append(ids, id)
+		} else {
+			envWarn(source, "unknown feature %q", name)
+		}
+	}
+	return ids
+}
+
+func envWarn(env, format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "cpuid: %s: %s\n", env, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FeatureArch is the architecture a feature belongs to.
+type FeatureArch uint8
+
+const (
+	ArchUnknown FeatureArch = iota
+	ArchX86
+	ArchARM64
+)
+
+// String returns the name of the architecture.
+func (a FeatureArch) String() string {
+	switch a {
+	case ArchX86:
+		return "x86"
+	case ArchARM64:
+		return "arm64"
+	}
+	return "unknown"
+}
+
+// FeatureCategory is the kind of a feature.
+type FeatureCategory uint8
+
+const (
+	CategorySystem         FeatureCategory = iota // General instructions and system features
+	CategorySIMD                                  // Vector and floating point instructions
+	CategoryCrypto                                // Cryptographic and random number instructions
+	CategorySecurity                              // Security features and vulnerability mitigations
+	CategoryVirtualization                        // Virtualization and confidential computing
+	CategoryPMU                                   // Performance monitoring
+)
+
+// String returns the name of the category.
+func (c FeatureCategory) String() string {
+	switch c {
+	case CategorySystem:
+		return "system"
+	case CategorySIMD:
+		return "SIMD"
+	case CategoryCrypto:
+		return "crypto"
+	case CategorySecurity:
+		return "security"
+	case CategoryVirtualization:
+		return "virtualization"
+	case CategoryPMU:
+		return "PMU"
+	}
+	return fmt.Sprintf("FeatureCategory(%d)", c)
+}
+
+// FeatureOrigin describes a register field a feature is detected from.
+type FeatureOrigin struct {
+	Leaf     uint32 // CPUID leaf (x86 only)
+	Subleaf  uint32 // CPUID subleaf (x86 only)
+	Register string // EAX, EBX, ECX or EDX on x86. HWCAP, HWCAP2 or an ID register on arm64.
+	Bit      uint8  // Lowest bit of the field
+	Width    uint8  // Number of bits in the field
+}
+
+// String returns the origin as "CPUID.(EAX=07H,ECX=0):EBX[5]" on x86
+// and "ID_AA64ISAR0_EL1[7:4]" or "HWCAP[3]" on arm64.
+func (o FeatureOrigin) String() string {
+	var sb strings.Builder
+	if strings.HasPrefix(o.Register, "E") && len(o.Register) == 3 {
+		fmt.Fprintf(&sb, "CPUID.(EAX=%02XH,ECX=%d):", o.Leaf, o.Subleaf)
+	}
+	sb.WriteString(o.Register)
+	if o.Width > 1 {
+		fmt.Fprintf(&sb, "[%d:%d]", int(o.Bit)+int(o.Width)-1, o.Bit)
+	} else {
+		fmt.Fprintf(&sb, "[%d]", o.Bit)
+	}
+	return sb.String()
+}
+
+// FeatureInfo contains information about a feature.
+type FeatureInfo struct {
+	Name        string          // Name of the feature, as returned by String
+	Description string          // Human readable description
+	Arch        FeatureArch     // Architecture of the feature
+	Category    FeatureCategory // Kind of feature
+	// Origins lists where the feature is detected from.
+	// Some features also require OS support or are adjusted based on the CPU model.
+	// Empty if the feature is derived from other information.
+	Origins []FeatureOrigin
+}
+
+// featureInfo is an entry in the generated featureInfos table.
+type featureInfo struct {
+	desc     string
+	arch     FeatureArch
+	category FeatureCategory
+	origins  []FeatureOrigin
+}
+
+// Info returns information about the feature.
+// For unknown features only the name is filled.
+func (i FeatureID) Info() FeatureInfo {
+	if i <= firstID || i >= lastID {
+		return FeatureInfo{Name: i.String()}
+	}
+	f := featureInfos[i]
+	return FeatureInfo{
+		Name:        i.String(),
+		Description: f.desc,
+		Arch:        f.arch,
+		Category:    f.category,
+		Origins:     append([]FeatureOrigin(nil), f.origins...),
+	}
+}
@@ -0,0 +1,243 @@
+// Code generated by "go run gen_featureinfo.go"; DO NOT EDIT.
+
+package cpuid
+
+var featureInfos = [lastID]featureInfo{
+	ADX:                            {desc: "Intel ADX (Multi-Precision Add-Carry Instruction Extensions)", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 19, Width: 1}}},
+	AESNI:                          {desc: "Advanced Encryption Standard New Instructions", arch: ArchX86, category: CategoryCrypto, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 25, Width: 1}}},
+	AMD3DNOW:                       {desc: "AMD 3DNOW", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x80000001, Subleaf: 0, Register: "EDX", Bit: 31, Width: 1}}},
+	AMD3DNOWEXT:                    {desc: "AMD 3DNowExt", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x80000001, Subleaf: 0, Register: "EDX", Bit: 30, Width: 1}}},
+	AMXBF16:                        {desc: "Tile computational operations on BFLOAT16 numbers", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 22, Width: 1}}},
+	AMXFP16:                        {desc: "Tile computational operations on FP16 numbers", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EAX", Bit: 21, Width: 1}}},
+	AMXINT8:                        {desc: "Tile computational operations on 8-bit integers", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 25, Width: 1}}},
+	AMXFP8:                         {desc: "Tile computational operations on FP8 numbers", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x1e, Subleaf: 1, Register: "EAX", Bit: 4, Width: 1}}},
+	AMXTILE:                        {desc: "Tile architecture", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 24, Width: 1}}},
+	AMXTF32:                        {desc: "Tile architecture", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EDX", Bit: 7, Width: 1}}},
+	AMXCOMPLEX:                     {desc: "Matrix Multiplication of TF32 Tiles into Packed Single Precision Tile", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EDX", Bit: 8, Width: 1}}},
+	AMXTRANSPOSE:                   {desc: "Tile multiply where the first operand is transposed", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EDX", Bit: 6, Width: 1}}},
+	APX_F:                          {desc: "Intel APX", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EDX", Bit: 21, Width: 1}}},
+	AVX:                            {desc: "AVX functions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 28, Width: 1}}},
+	AVX10:                          {desc: "If set the Intel AVX10 Converged Vector ISA is supported", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EDX", Bit: 19, Width: 1}}},
+	AVX10_128:                      {desc: "If set indicates that AVX10 128-bit vector support is present", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x24, Subleaf: 0, Register: "EBX", Bit: 16, Width: 1}}},
+	AVX10_256:                      {desc: "If set indicates that AVX10 256-bit vector support is present", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x24, Subleaf: 0, Register: "EBX", Bit: 17, Width: 1}}},
+	AVX10_512:                      {desc: "If set indicates that AVX10 512-bit vector support is present", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x24, Subleaf: 0, Register: "EBX", Bit: 18, Width: 1}}},
+	AVX2:                           {desc: "AVX2 functions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 5, Width: 1}}},
+	AVX512BF16:                     {desc: "AVX-512 BFLOAT16 Instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EAX", Bit: 5, Width: 1}}},
+	AVX512BITALG:                   {desc: "AVX-512 Bit Algorithms", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 12, Width: 1}}},
+	AVX512BMM:                      {desc: "AVX-512 Bit Manipulation Instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x80000021, Subleaf: 0, Register: "EAX", Bit: 23, Width: 1}}},
+	AVX512BW:                       {desc: "AVX-512 Byte and Word Instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 30, Width: 1}}},
+	AVX512CD:                       {desc: "AVX-512 Conflict Detection Instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 28, Width: 1}}},
+	AVX512DQ:                       {desc: "AVX-512 Doubleword and Quadword Instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 17, Width: 1}}},
+	AVX512ER:                       {desc: "AVX-512 Exponential and Reciprocal Instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 27, Width: 1}}},
+	AVX512F:                        {desc: "AVX-512 Foundation", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 16, Width: 1}}},
+	AVX512FP16:                     {desc: "AVX-512 FP16 Instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 23, Width: 1}}},
+	AVX512IFMA:                     {desc: "AVX-512 Integer Fused Multiply-Add Instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 21, Width: 1}}},
+	AVX512PF:                       {desc: "AVX-512 Prefetch Instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 26, Width: 1}}},
+	AVX512VBMI:                     {desc: "AVX-512 Vector Bit Manipulation Instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 1, Width: 1}}},
+	AVX512VBMI2:                    {desc: "AVX-512 Vector Bit Manipulation Instructions, Version 2", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 6, Width: 1}}},
+	AVX512VL:                       {desc: "AVX-512 Vector Length Extensions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 31, Width: 1}}},
+	AVX512VNNI:                     {desc: "AVX-512 Vector Neural Network Instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 11, Width: 1}}},
+	AVX512VP2INTERSECT:             {desc: "AVX-512 Intersect for D/Q", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 8, Width: 1}}},
+	AVX512VPOPCNTDQ:                {desc: "AVX-512 Vector Population Count Doubleword and Quadword", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 14, Width: 1}}},
+	AVXIFMA:                        {desc: "AVX-IFMA instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EAX", Bit: 23, Width: 1}}},
+	AVXNECONVERT:                   {desc: "AVX-NE-CONVERT instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EDX", Bit: 5, Width: 1}}},
+	AVXSLOW:                        {desc: "Indicates the CPU performs 2 128 bit operations instead of one", arch: ArchX86, category: CategorySIMD},
+	AVXVNNI:                        {desc: "AVX (VEX encoded) VNNI neural network instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EAX", Bit: 4, Width: 1}}},
+	AVXVNNIINT8:                    {desc: "AVX-VNNI-INT8 instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EDX", Bit: 4, Width: 1}}},
+	AVXVNNIINT16:                   {desc: "AVX-VNNI-INT16 instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EDX", Bit: 10, Width: 1}}},
+	BHI_CTRL:                       {desc: "Branch History Injection and Intra-mode Branch Target Injection / CVE-2022-0001, CVE-2022-0002 / INTEL-SA-00598", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 2, Register: "EDX", Bit: 4, Width: 1}}},
+	BMI1:                           {desc: "Bit Manipulation Instruction Set 1", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 3, Width: 1}}},
+	BMI2:                           {desc: "Bit Manipulation Instruction Set 2", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 8, Width: 1}}},
+	CETIBT:                         {desc: "Intel CET Indirect Branch Tracking", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 20, Width: 1}}},
+	CETSS:                          {desc: "Intel CET Shadow Stack", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 7, Width: 1}}},
+	CLDEMOTE:                       {desc: "Cache Line Demote", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 25, Width: 1}}},
+	CLMUL:                          {desc: "Carry-less Multiplication", arch: ArchX86, category: CategoryCrypto, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 1, Width: 1}}},
+	CLZERO:                         {desc: "CLZERO instruction supported", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 0, Width: 1}}},
+	CMOV:                           {desc: "i686 CMOV", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "EDX", Bit: 15, Width: 1}}},
+	CMPCCXADD:                      {desc: "CMPCCXADD instructions", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EAX", Bit: 7, Width: 1}}},
+	CMPSB_SCADBS_SHORT:             {desc: "Fast short CMPSB and SCASB", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EAX", Bit: 12, Width: 1}}},
+	CMPXCHG8:                       {desc: "CMPXCHG8 instruction", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "EDX", Bit: 8, Width: 1}}},
+	CPBOOST:                        {desc: "Core Performance Boost", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000007, Subleaf: 0, Register: "EDX", Bit: 9, Width: 1}}},
+	CPPC:                           {desc: "AMD: Collaborative Processor Performance Control", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 27, Width: 1}}},
+	CX16:                           {desc: "CMPXCHG16B Instruction", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 13, Width: 1}}},
+	EFER_LMSLE_UNS:                 {desc: "AMD: =Core::X86::Msr::EFER[LMSLE] is not supported, and MBZ", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 20, Width: 1}}},
+	ENQCMD:                         {desc: "Enqueue Command", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 29, Width: 1}}},
+	ERMS:                           {desc: "Enhanced REP MOVSB/STOSB", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 9, Width: 1}}},
+	F16C:                           {desc: "Half-precision floating-point conversion", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 29, Width: 1}}},
+	FLUSH_L1D:                      {desc: "Flush L1D cache", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 28, Width: 1}}},
+	FMA3:                           {desc: "Intel FMA 3. Requires AVX.", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 12, Width: 1}}},
+	FMA4:                           {desc: "Bulldozer FMA4 functions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x80000001, Subleaf: 0, Register: "ECX", Bit: 16, Width: 1}}},
+	FP128:                          {desc: "AMD: When set, the internal FP/SIMD execution datapath is no more than 128-bits wide", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x8000001a, Subleaf: 0, Register: "EAX", Bit: 0, Width: 1}}},
+	FP256:                          {desc: "AMD: When set, the internal FP/SIMD execution datapath is no more than 256-bits wide", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x8000001a, Subleaf: 0, Register: "EAX", Bit: 2, Width: 1}}},
+	FSRM:                           {desc: "Fast Short Rep Mov", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 4, Width: 1}}},
+	FXSR:                           {desc: "FXSAVE, FXRESTOR instructions, CR4 bit 9", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "EDX", Bit: 24, Width: 1}, {Leaf: 0x80000001, Subleaf: 0, Register: "EDX", Bit: 24, Width: 1}}},
+	FXSROPT:                        {desc: "FXSAVE/FXRSTOR optimizations", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "EDX", Bit: 25, Width: 1}, {Leaf: 0x80000001, Subleaf: 0, Register: "EDX", Bit: 25, Width: 1}}},
+	GFNI:                           {desc: "Galois Field New Instructions. May require other features (AVX, AVX512VL,AVX512F) based on usage.", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 8, Width: 1}}},
+	HLE:                            {desc: "Hardware Lock Elision", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 4, Width: 1}}},
+	HRESET:                         {desc: "If set CPU supports history reset and the IA32_HRESET_ENABLE MSR", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EAX", Bit: 22, Width: 1}}},
+	HTT:                            {desc: "Hyperthreading (enabled)", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "EDX", Bit: 28, Width: 1}}},
+	HWA:                            {desc: "Hardware assert supported. Indicates support for MSRC001_10", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000007, Subleaf: 0, Register: "EBX", Bit: 2, Width: 1}}},
+	HYBRID_CPU:                     {desc: "This part has CPUs of more than one type.", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 15, Width: 1}}},
+	HYPERVISOR:                     {desc: "This bit has been reserved by Intel & AMD for use by hypervisors", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 31, Width: 1}}},
+	IA32_ARCH_CAP:                  {desc: "IA32_ARCH_CAPABILITIES MSR (Intel)", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 29, Width: 1}}},
+	IA32_CORE_CAP:                  {desc: "IA32_CORE_CAPABILITIES MSR", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 30, Width: 1}}},
+	IBPB:                           {desc: "Indirect Branch Restricted Speculation (IBRS) and Indirect Branch Predictor Barrier (IBPB)", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 26, Width: 1}, {Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 12, Width: 1}}},
+	IBPB_BRTYPE:                    {desc: "Indicates that MSR 49h (PRED_CMD) bit 0 (IBPB) flushes all branch type predictions from the CPU branch predictor", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x80000021, Subleaf: 0, Register: "EAX", Bit: 28, Width: 1}}},
+	IBRS:                           {desc: "AMD: Indirect Branch Restricted Speculation", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 14, Width: 1}}},
+	IBRS_PREFERRED:                 {desc: "AMD: IBRS is preferred over software solution", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 18, Width: 1}}},
+	IBRS_PROVIDES_SMP:              {desc: "AMD: IBRS provides Same Mode Protection", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 19, Width: 1}}},
+	IBS:                            {desc: "Instruction Based Sampling (AMD)", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0x80000001, Subleaf: 0, Register: "ECX", Bit: 10, Width: 1}}},
+	IBSBRNTRGT:                     {desc: "Instruction Based Sampling Feature (AMD)", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0x8000001b, Subleaf: 0, Register: "EAX", Bit: 5, Width: 1}}},
+	IBSFETCHSAM:                    {desc: "Instruction Based Sampling Feature (AMD)", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0x8000001b, Subleaf: 0, Register: "EAX", Bit: 1, Width: 1}}},
+	IBSFFV:                         {desc: "Instruction Based Sampling Feature (AMD)", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0x8000001b, Subleaf: 0, Register: "EAX", Bit: 0, Width: 1}}},
+	IBSOPCNT:                       {desc: "Instruction Based Sampling Feature (AMD)", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0x8000001b, Subleaf: 0, Register: "EAX", Bit: 4, Width: 1}}},
+	IBSOPCNTEXT:                    {desc: "Instruction Based Sampling Feature (AMD)", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0x8000001b, Subleaf: 0, Register: "EAX", Bit: 6, Width: 1}}},
+	IBSOPSAM:                       {desc: "Instruction Based Sampling Feature (AMD)", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0x8000001b, Subleaf: 0, Register: "EAX", Bit: 2, Width: 1}}},
+	IBSRDWROPCNT:                   {desc: "Instruction Based Sampling Feature (AMD)", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0x8000001b, Subleaf: 0, Register: "EAX", Bit: 3, Width: 1}}},
+	IBSRIPINVALIDCHK:               {desc: "Instruction Based Sampling Feature (AMD)", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0x8000001b, Subleaf: 0, Register: "EAX", Bit: 7, Width: 1}}},
+	IBS_FETCH_CTLX:                 {desc: "AMD: IBS fetch control extended MSR supported", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0x8000001b, Subleaf: 0, Register: "EAX", Bit: 9, Width: 1}}},
+	IBS_OPDATA4:                    {desc: "AMD: IBS op data 4 MSR supported", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0x8000001b, Subleaf: 0, Register: "EAX", Bit: 10, Width: 1}}},
+	IBS_OPFUSE:                     {desc: "AMD: Indicates support for IbsOpFuse", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0x8000001b, Subleaf: 0, Register: "EAX", Bit: 8, Width: 1}}},
+	IBS_PREVENTHOST:                {desc: "Disallowing IBS use by the host supported", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0x8000001f, Subleaf: 0, Register: "EAX", Bit: 15, Width: 1}}},
+	IBS_ZEN4:                       {desc: "AMD: Fetch and Op IBS support IBS extensions added with Zen4", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0x8000001b, Subleaf: 0, Register: "EAX", Bit: 11, Width: 1}}},
+	IDPRED_CTRL:                    {desc: "IPRED_DIS", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 2, Register: "EDX", Bit: 1, Width: 1}}},
+	INT_WBINVD:                     {desc: "WBINVD/WBNOINVD are interruptible.", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 13, Width: 1}}},
+	INVLPGB:                        {desc: "NVLPGB and TLBSYNC instruction supported", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 3, Width: 1}}},
+	KEYLOCKER:                      {desc: "Key locker", arch: ArchX86, category: CategoryCrypto, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 23, Width: 1}}},
+	KEYLOCKERW:                     {desc: "Key locker wide", arch: ArchX86, category: CategoryCrypto, origins: []FeatureOrigin{{Leaf: 0x19, Subleaf: 0, Register: "EBX", Bit: 2, Width: 1}}},
+	LAHF:                           {desc: "LAHF/SAHF in long mode", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000001, Subleaf: 0, Register: "ECX", Bit: 0, Width: 1}}},
+	LAM:                            {desc: "If set, CPU supports Linear Address Masking", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EAX", Bit: 26, Width: 1}}},
+	LBRVIRT:                        {desc: "LBR virtualization", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000000a, Subleaf: 0, Register: "EDX", Bit: 1, Width: 1}}},
+	LZCNT:                          {desc: "LZCNT instruction", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000001, Subleaf: 0, Register: "ECX", Bit: 5, Width: 1}}},
+	MCAOVERFLOW:                    {desc: "MCA overflow recovery support.", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000007, Subleaf: 0, Register: "EBX", Bit: 0, Width: 1}}},
+	MCDT_NO:                        {desc: "Processor do not exhibit MXCSR Configuration Dependent Timing behavior and do not need to mitigate it.", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 2, Register: "EDX", Bit: 5, Width: 1}}},
+	MCOMMIT:                        {desc: "MCOMMIT instruction supported", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 8, Width: 1}}},
+	MD_CLEAR:                       {desc: "VERW clears CPU buffers", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 10, Width: 1}}},
+	MMX:                            {desc: "standard MMX", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "EDX", Bit: 23, Width: 1}, {Leaf: 0x80000001, Subleaf: 0, Register: "EDX", Bit: 23, Width: 1}}},
+	MMXEXT:                         {desc: "SSE integer functions or AMD MMX ext", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x80000001, Subleaf: 0, Register: "EDX", Bit: 22, Width: 1}}},
+	MOVBE:                          {desc: "MOVBE instruction (big-endian)", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 22, Width: 1}}},
+	MOVDIR64B:                      {desc: "Move 64 Bytes as Direct Store", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 28, Width: 1}}},
+	MOVDIRI:                        {desc: "Move Doubleword as Direct Store", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 27, Width: 1}}},
+	MOVSB_ZL:                       {desc: "Fast Zero-Length MOVSB", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EAX", Bit: 10, Width: 1}}},
+	MOVU:                           {desc: "AMD: MOVU SSE instructions are more efficient and should be preferred to SSE MOVL/MOVH. MOVUPS is more efficient than MOVLPS/MOVHPS. MOVUPD is more efficient than MOVLPD/MOVHPD", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x8000001a, Subleaf: 0, Register: "EAX", Bit: 1, Width: 1}}},
+	MPX:                            {desc: "Intel MPX (Memory Protection Extensions)", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 14, Width: 1}}},
+	MSRIRC:                         {desc: "Instruction Retired Counter MSR available", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 1, Width: 1}}},
+	MSRLIST:                        {desc: "Read/Write List of Model Specific Registers", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EAX", Bit: 27, Width: 1}}},
+	MSR_PAGEFLUSH:                  {desc: "Page Flush MSR available", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x8000001f, Subleaf: 0, Register: "EAX", Bit: 2, Width: 1}}},
+	NRIPS:                          {desc: "Indicates support for NRIP save on VMEXIT", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000000a, Subleaf: 0, Register: "EDX", Bit: 3, Width: 1}}},
+	NX:                             {desc: "NX (No-Execute) bit", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x80000001, Subleaf: 0, Register: "EDX", Bit: 20, Width: 1}}},
+	OSXSAVE:                        {desc: "XSAVE enabled by OS", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 27, Width: 1}}},
+	PCONFIG:                        {desc: "PCONFIG for Intel Multi-Key Total Memory Encryption", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 18, Width: 1}}},
+	POPCNT:                         {desc: "POPCNT instruction", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 23, Width: 1}, {Leaf: 0x80000001, Subleaf: 0, Register: "ECX", Bit: 5, Width: 1}}},
+	PPIN:                           {desc: "AMD: Protected Processor Inventory Number support. Indicates that Protected Processor Inventory Number (PPIN) capability can be enabled", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 23, Width: 1}}},
+	PREFETCHI:                      {desc: "PREFETCHIT0/1 instructions", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EDX", Bit: 14, Width: 1}}},
+	PSFD:                           {desc: "Predictive Store Forward Disable", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 2, Register: "EDX", Bit: 0, Width: 1}, {Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 28, Width: 1}}},
+	RDPRU:                          {desc: "RDPRU instruction supported", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 4, Width: 1}}},
+	RDRAND:                         {desc: "RDRAND instruction is available", arch: ArchX86, category: CategoryCrypto, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 30, Width: 1}}},
+	RDSEED:                         {desc: "RDSEED instruction is available", arch: ArchX86, category: CategoryCrypto, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 18, Width: 1}}},
+	RDTSCP:                         {desc: "RDTSCP Instruction", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000001, Subleaf: 0, Register: "EDX", Bit: 27, Width: 1}}},
+	RRSBA_CTRL:                     {desc: "Restricted RSB Alternate", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 2, Register: "EDX", Bit: 2, Width: 1}}},
+	RTM:                            {desc: "Restricted Transactional Memory", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 11, Width: 1}}},
+	RTM_ALWAYS_ABORT:               {desc: "Indicates that the loaded microcode is forcing RTM abort.", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 11, Width: 1}}},
+	SBPB:                           {desc: "Indicates support for the Selective Branch Predictor Barrier", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x80000021, Subleaf: 0, Register: "EAX", Bit: 27, Width: 1}}},
+	SERIALIZE:                      {desc: "Serialize Instruction Execution", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 14, Width: 1}}},
+	SEV:                            {desc: "AMD Secure Encrypted Virtualization supported", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000001f, Subleaf: 0, Register: "EAX", Bit: 1, Width: 1}}},
+	SEV_64BIT:                      {desc: "AMD SEV guest execution only allowed from a 64-bit host", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000001f, Subleaf: 0, Register: "EAX", Bit: 11, Width: 1}}},
+	SEV_ALTERNATIVE:                {desc: "AMD SEV Alternate Injection supported", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000001f, Subleaf: 0, Register: "EAX", Bit: 13, Width: 1}}},
+	SEV_DEBUGSWAP:                  {desc: "Full debug state swap supported for SEV-ES guests", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000001f, Subleaf: 0, Register: "EAX", Bit: 14, Width: 1}}},
+	SEV_ES:                         {desc: "AMD SEV Encrypted State supported", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000001f, Subleaf: 0, Register: "EAX", Bit: 3, Width: 1}}},
+	SEV_RESTRICTED:                 {desc: "AMD SEV Restricted Injection supported", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000001f, Subleaf: 0, Register: "EAX", Bit: 12, Width: 1}}},
+	SEV_SNP:                        {desc: "AMD SEV Secure Nested Paging supported", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000001f, Subleaf: 0, Register: "EAX", Bit: 4, Width: 1}}},
+	SGX:                            {desc: "Software Guard Extensions", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 2, Width: 1}}},
+	SGXLC:                          {desc: "Software Guard Extensions Launch Control", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 30, Width: 1}}},
+	SGXPQC:                         {desc: "Software Guard Extensions 256-bit Encryption", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x12, Subleaf: 0, Register: "EAX", Bit: 12, Width: 1}}},
+	SHA:                            {desc: "Intel SHA Extensions", arch: ArchX86, category: CategoryCrypto, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EBX", Bit: 29, Width: 1}}},
+	SME:                            {desc: "AMD Secure Memory Encryption supported", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x8000001f, Subleaf: 0, Register: "EAX", Bit: 0, Width: 1}}},
+	SME_COHERENT:                   {desc: "AMD Hardware cache coherency across encryption domains enforced", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x8000001f, Subleaf: 0, Register: "EAX", Bit: 10, Width: 1}}},
+	SM3_X86:                        {desc: "SM3 instructions", arch: ArchX86, category: CategoryCrypto, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EAX", Bit: 1, Width: 1}}},
+	SM4_X86:                        {desc: "SM4 instructions", arch: ArchX86, category: CategoryCrypto, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EAX", Bit: 2, Width: 1}}},
+	SPEC_CTRL_SSBD:                 {desc: "Speculative Store Bypass Disable", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 31, Width: 1}, {Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 24, Width: 1}}},
+	SRBDS_CTRL:                     {desc: "SRBDS mitigation MSR available", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 9, Width: 1}}},
+	SRSO_MSR_FIX:                   {desc: "Indicates that software may use MSR BP_CFG[BpSpecReduce] to mitigate SRSO.", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x80000021, Subleaf: 0, Register: "EAX", Bit: 31, Width: 1}}},
+	SRSO_NO:                        {desc: "Indicates the CPU is not subject to the SRSO vulnerability", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x80000021, Subleaf: 0, Register: "EAX", Bit: 29, Width: 1}}},
+	SRSO_USER_KERNEL_NO:            {desc: "Indicates the CPU is not subject to the SRSO vulnerability across user/kernel boundaries", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x80000021, Subleaf: 0, Register: "EAX", Bit: 30, Width: 1}}},
+	SSE:                            {desc: "SSE functions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "EDX", Bit: 25, Width: 1}}},
+	SSE2:                           {desc: "P4 SSE functions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "EDX", Bit: 26, Width: 1}}},
+	SSE3:                           {desc: "Prescott SSE3 functions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 0, Width: 1}}},
+	SSE4:                           {desc: "Penryn SSE4.1 functions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 19, Width: 1}}},
+	SSE42:                          {desc: "Nehalem SSE4.2 functions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 20, Width: 1}}},
+	SSE4A:                          {desc: "AMD Barcelona microarchitecture SSE4a instructions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x80000001, Subleaf: 0, Register: "ECX", Bit: 6, Width: 1}}},
+	SSSE3:                          {desc: "Conroe SSSE3 functions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 9, Width: 1}}},
+	STIBP:                          {desc: "Single Thread Indirect Branch Predictors", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 27, Width: 1}, {Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 15, Width: 1}}},
+	STIBP_ALWAYSON:                 {desc: "AMD: Single Thread Indirect Branch Prediction Mode has Enhanced Performance and may be left Always On", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 17, Width: 1}}},
+	STOSB_SHORT:                    {desc: "Fast short STOSB", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EAX", Bit: 11, Width: 1}}},
+	SUCCOR:                         {desc: "Software uncorrectable error containment and recovery capability.", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000007, Subleaf: 0, Register: "EBX", Bit: 1, Width: 1}}},
+	SVM:                            {desc: "AMD Secure Virtual Machine", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x80000001, Subleaf: 0, Register: "ECX", Bit: 2, Width: 1}}},
+	SVMDA:                          {desc: "Indicates support for the SVM decode assists.", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000000a, Subleaf: 0, Register: "EDX", Bit: 7, Width: 1}}},
+	SVMFBASID:                      {desc: "SVM, Indicates that TLB flush events, including CR3 writes and CR4.PGE toggles, flush only the current ASID's TLB entries. Also indicates support for the extended VMCBTLB_Control", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000000a, Subleaf: 0, Register: "EDX", Bit: 6, Width: 1}}},
+	SVML:                           {desc: "AMD SVM lock. Indicates support for SVM-Lock.", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000000a, Subleaf: 0, Register: "EDX", Bit: 2, Width: 1}}},
+	SVMNP:                          {desc: "AMD SVM nested paging", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000000a, Subleaf: 0, Register: "EDX", Bit: 0, Width: 1}}},
+	SVMPF:                          {desc: "SVM pause intercept filter. Indicates support for the pause intercept filter", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000000a, Subleaf: 0, Register: "EDX", Bit: 10, Width: 1}}},
+	SVMPFT:                         {desc: "SVM PAUSE filter threshold. Indicates support for the PAUSE filter cycle count threshold", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000000a, Subleaf: 0, Register: "EDX", Bit: 12, Width: 1}}},
+	SYSCALL:                        {desc: "System-Call Extension (SCE): SYSCALL and SYSRET instructions.", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000001, Subleaf: 0, Register: "EDX", Bit: 11, Width: 1}}},
+	SYSEE:                          {desc: "SYSENTER and SYSEXIT instructions", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "EDX", Bit: 11, Width: 1}}},
+	TBM:                            {desc: "AMD Trailing Bit Manipulation", arch: ArchX86, category: CategorySystem},
+	TDX_GUEST:                      {desc: "Intel Trust Domain Extensions Guest", arch: ArchX86, category: CategoryVirtualization},
+	TLB_FLUSH_NESTED:               {desc: "AMD: Flushing includes all the nested translations for guest translations", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 21, Width: 1}}},
+	TME:                            {desc: "Intel Total Memory Encryption. The following MSRs are supported: IA32_TME_CAPABILITY, IA32_TME_ACTIVATE, IA32_TME_EXCLUDE_MASK, and IA32_TME_EXCLUDE_BASE.", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 13, Width: 1}}},
+	TOPEXT:                         {desc: "TopologyExtensions: topology extensions support. Indicates support for CPUID Fn8000_001D_EAX_x[N:0]-CPUID Fn8000_001E_EDX.", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000001, Subleaf: 0, Register: "ECX", Bit: 22, Width: 1}}},
+	TSA_L1_NO:                      {desc: "AMD only: Not vulnerable to TSA-L1", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x80000021, Subleaf: 0, Register: "ECX", Bit: 1, Width: 1}}},
+	TSA_SQ_NO:                      {desc: "AM onlyD: Not vulnerable to TSA-SQ", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x80000021, Subleaf: 0, Register: "ECX", Bit: 2, Width: 1}}},
+	TSA_VERW_CLEAR:                 {desc: "If set, the memory form of the VERW instruction may be used to help mitigate TSA", arch: ArchX86, category: CategorySecurity, origins: []FeatureOrigin{{Leaf: 0x80000021, Subleaf: 0, Register: "EAX", Bit: 5, Width: 1}}},
+	TSCRATEMSR:                     {desc: "MSR based TSC rate control. Indicates support for MSR TSC ratio MSRC000_0104", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000000a, Subleaf: 0, Register: "EDX", Bit: 4, Width: 1}}},
+	TSXLDTRK:                       {desc: "Intel TSX Suspend Load Address Tracking", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "EDX", Bit: 16, Width: 1}}},
+	VAES:                           {desc: "Vector AES. AVX(512) versions requires additional checks.", arch: ArchX86, category: CategoryCrypto, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 9, Width: 1}}},
+	VMCBCLEAN:                      {desc: "VMCB clean bits. Indicates support for VMCB clean bits.", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000000a, Subleaf: 0, Register: "EDX", Bit: 5, Width: 1}}},
+	VMPL:                           {desc: "AMD VM Permission Levels supported", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000001f, Subleaf: 0, Register: "EAX", Bit: 5, Width: 1}}},
+	VMSA_REGPROT:                   {desc: "AMD VMSA Register Protection supported", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000001f, Subleaf: 0, Register: "EAX", Bit: 24, Width: 1}}},
+	VMX:                            {desc: "Virtual Machine Extensions", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 5, Width: 1}}},
+	VPCLMULQDQ:                     {desc: "Carry-Less Multiplication Quadword. Requires AVX for 3 register versions.", arch: ArchX86, category: CategoryCrypto, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 10, Width: 1}}},
+	VTE:                            {desc: "AMD Virtual Transparent Encryption supported", arch: ArchX86, category: CategoryVirtualization, origins: []FeatureOrigin{{Leaf: 0x8000001f, Subleaf: 0, Register: "EAX", Bit: 16, Width: 1}}},
+	WAITPKG:                        {desc: "TPAUSE, UMONITOR, UMWAIT", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 0, Register: "ECX", Bit: 5, Width: 1}}},
+	WBNOINVD:                       {desc: "Write Back and Do Not Invalidate Cache", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x80000008, Subleaf: 0, Register: "EBX", Bit: 9, Width: 1}}},
+	WRMSRNS:                        {desc: "Non-Serializing Write to Model Specific Register", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x7, Subleaf: 1, Register: "EAX", Bit: 19, Width: 1}}},
+	X87:                            {desc: "FPU", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "EDX", Bit: 0, Width: 1}}},
+	XGETBV1:                        {desc: "Supports XGETBV with ECX = 1", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0xd, Subleaf: 1, Register: "EAX", Bit: 2, Width: 1}}},
+	XOP:                            {desc: "Bulldozer XOP functions", arch: ArchX86, category: CategorySIMD, origins: []FeatureOrigin{{Leaf: 0x80000001, Subleaf: 0, Register: "ECX", Bit: 11, Width: 1}}},
+	XSAVE:                          {desc: "XSAVE, XRESTOR, XSETBV, XGETBV", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0x1, Subleaf: 0, Register: "ECX", Bit: 26, Width: 1}}},
+	XSAVEC:                         {desc: "Supports XSAVEC and the compacted form of XRSTOR.", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0xd, Subleaf: 1, Register: "EAX", Bit: 1, Width: 1}}},
+	XSAVEOPT:                       {desc: "XSAVEOPT available", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0xd, Subleaf: 1, Register: "EAX", Bit: 0, Width: 1}}},
+	XSAVES:                         {desc: "Supports XSAVES/XRSTORS and IA32_XSS", arch: ArchX86, category: CategorySystem, origins: []FeatureOrigin{{Leaf: 0xd, Subleaf: 1, Register: "EAX", Bit: 3, Width: 1}}},
+	AESARM:                         {desc: "AES instructions", arch: ArchARM64, category: CategoryCrypto, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 4, Width: 4}, {Register: "HWCAP", Bit: 3, Width: 1}}},
+	ARMCPUID:                       {desc: "Some CPU ID registers readable at user-level", arch: ArchARM64, category: CategorySystem, origins: []FeatureOrigin{{Register: "HWCAP", Bit: 11, Width: 1}}},
+	ASIMD:                          {desc: "Advanced SIMD", arch: ArchARM64, category: CategorySIMD, origins: []FeatureOrigin{{Register: "ID_AA64PFR0_EL1", Bit: 20, Width: 4}, {Register: "HWCAP", Bit: 1, Width: 1}}},
+	ASIMDDP:                        {desc: "SIMD Dot Product", arch: ArchARM64, category: CategorySIMD, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 44, Width: 4}, {Register: "HWCAP", Bit: 20, Width: 1}}},
+	ASIMDHP:                        {desc: "Advanced SIMD half-precision floating point", arch: ArchARM64, category: CategorySIMD, origins: []FeatureOrigin{{Register: "ID_AA64PFR0_EL1", Bit: 20, Width: 4}, {Register: "HWCAP", Bit: 10, Width: 1}}},
+	ASIMDRDM:                       {desc: "Rounding Double Multiply Accumulate/Subtract (SQRDMLAH/SQRDMLSH)", arch: ArchARM64, category: CategorySIMD, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 28, Width: 4}, {Register: "HWCAP", Bit: 12, Width: 1}}},
+	ATOMICS:                        {desc: "Large System Extensions (LSE)", arch: ArchARM64, category: CategorySystem, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 20, Width: 4}, {Register: "HWCAP", Bit: 8, Width: 1}}},
+	CRC32:                          {desc: "CRC32/CRC32C instructions", arch: ArchARM64, category: CategorySystem, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 16, Width: 4}, {Register: "HWCAP", Bit: 7, Width: 1}}},
+	DCPOP:                          {desc: "Data cache clean to Point of Persistence (DC CVAP)", arch: ArchARM64, category: CategorySystem, origins: []FeatureOrigin{{Register: "ID_AA64ISAR1_EL1", Bit: 0, Width: 4}, {Register: "HWCAP", Bit: 16, Width: 1}}},
+	EVTSTRM:                        {desc: "Generic timer", arch: ArchARM64, category: CategorySystem, origins: []FeatureOrigin{{Register: "HWCAP", Bit: 2, Width: 1}}},
+	FCMA:                           {desc: "Floating point complex number addition and multiplication", arch: ArchARM64, category: CategorySIMD, origins: []FeatureOrigin{{Register: "ID_AA64ISAR1_EL1", Bit: 16, Width: 4}, {Register: "HWCAP", Bit: 14, Width: 1}}},
+	FHM:                            {desc: "FMLAL and FMLSL instructions", arch: ArchARM64, category: CategorySIMD, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 48, Width: 4}, {Register: "HWCAP", Bit: 23, Width: 1}}},
+	FP:                             {desc: "Single-precision and double-precision floating point", arch: ArchARM64, category: CategorySIMD, origins: []FeatureOrigin{{Register: "ID_AA64PFR0_EL1", Bit: 16, Width: 4}, {Register: "HWCAP", Bit: 0, Width: 1}}},
+	FPHP:                           {desc: "Half-precision floating point", arch: ArchARM64, category: CategorySIMD, origins: []FeatureOrigin{{Register: "ID_AA64PFR0_EL1", Bit: 20, Width: 4}, {Register: "HWCAP", Bit: 9, Width: 1}}},
+	GPA:                            {desc: "Generic Pointer Authentication", arch: ArchARM64, category: CategorySecurity, origins: []FeatureOrigin{{Register: "ID_AA64ISAR1_EL1", Bit: 28, Width: 4}}},
+	JSCVT:                          {desc: "Javascript-style double->int convert (FJCVTZS)", arch: ArchARM64, category: CategorySIMD, origins: []FeatureOrigin{{Register: "ID_AA64ISAR1_EL1", Bit: 12, Width: 4}, {Register: "HWCAP", Bit: 13, Width: 1}}},
+	LRCPC:                          {desc: "Weaker release consistency (LDAPR, etc)", arch: ArchARM64, category: CategorySystem, origins: []FeatureOrigin{{Register: "ID_AA64ISAR1_EL1", Bit: 20, Width: 4}, {Register: "HWCAP", Bit: 15, Width: 1}}},
+	PMULL:                          {desc: "Polynomial Multiply instructions (PMULL/PMULL2)", arch: ArchARM64, category: CategoryCrypto, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 4, Width: 4}, {Register: "HWCAP", Bit: 4, Width: 1}}},
+	RNDR:                           {desc: "Random Number instructions", arch: ArchARM64, category: CategoryCrypto, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 60, Width: 4}, {Register: "HWCAP2", Bit: 16, Width: 1}}},
+	TLB:                            {desc: "Outer Shareable and TLB range maintenance instructions", arch: ArchARM64, category: CategorySystem, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 56, Width: 4}}},
+	TS:                             {desc: "Flag manipulation instructions", arch: ArchARM64, category: CategorySystem, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 52, Width: 4}}},
+	SHA1:                           {desc: "SHA-1 instructions (SHA1C, etc)", arch: ArchARM64, category: CategoryCrypto, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 8, Width: 4}, {Register: "HWCAP", Bit: 5, Width: 1}}},
+	SHA2:                           {desc: "SHA-2 instructions (SHA256H, etc)", arch: ArchARM64, category: CategoryCrypto, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 12, Width: 4}, {Register: "HWCAP", Bit: 6, Width: 1}}},
+	SHA3:                           {desc: "SHA-3 instructions (EOR3, RAXI, XAR, BCAX)", arch: ArchARM64, category: CategoryCrypto, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 32, Width: 4}, {Register: "HWCAP", Bit: 17, Width: 1}}},
+	SHA512:                         {desc: "SHA512 instructions", arch: ArchARM64, category: CategoryCrypto, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 12, Width: 4}, {Register: "HWCAP", Bit: 21, Width: 1}}},
+	SM3:                            {desc: "SM3 instructions", arch: ArchARM64, category: CategoryCrypto, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 36, Width: 4}, {Register: "HWCAP", Bit: 18, Width: 1}}},
+	SM4:                            {desc: "SM4 instructions", arch: ArchARM64, category: CategoryCrypto, origins: []FeatureOrigin{{Register: "ID_AA64ISAR0_EL1", Bit: 40, Width: 4}, {Register: "HWCAP", Bit: 19, Width: 1}}},
+	SVE:                            {desc: "Scalable Vector Extension", arch: ArchARM64, category: CategorySIMD, origins: []FeatureOrigin{{Register: "ID_AA64PFR0_EL1", Bit: 32, Width: 4}, {Register: "HWCAP", Bit: 22, Width: 1}}},
+	PMU_FIXEDCOUNTER_CYCLES:        {desc: "Fixed function counter for core cycles", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0xa, Subleaf: 0, Register: "EBX", Bit: 1, Width: 1}}},
+	PMU_FIXEDCOUNTER_REFCYCLES:     {desc: "Fixed function counter for reference cycles", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0xa, Subleaf: 0, Register: "EBX", Bit: 2, Width: 1}}},
+	PMU_FIXEDCOUNTER_INSTRUCTIONS:  {desc: "Fixed function counter for instructions retired", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0xa, Subleaf: 0, Register: "EBX", Bit: 0, Width: 1}}},
+	PMU_FIXEDCOUNTER_TOPDOWN_SLOTS: {desc: "Fixed function counter for topdown slots", arch: ArchX86, category: CategoryPMU, origins: []FeatureOrigin{{Leaf: 0xa, Subleaf: 0, Register: "EBX", Bit: 3, Width: 1}}},
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// FeatureSet is a set of features.
+// The zero value is an empty set.
+// FeatureSet is a value type and can be compared with ==.
+type FeatureSet struct {
+	s flagSet
+}
+
+// NewFeatureSet returns a set containing the supplied features.
+func NewFeatureSet(ids ...FeatureID) FeatureSet {
+	return FeatureSet{s: flagSetWith(ids...)}
+}
+
+// Features returns the enabled features of the CPU.
+func (c CPUInfo) Features() FeatureSet {
+	return FeatureSet{s: c.featureSet}
+}
+
+// Union returns features present in either f or other.
+func (f FeatureSet) Union(other FeatureSet) FeatureSet {
+	f.s.or(other.s)
+	return f
+}
+
+// Intersect returns features present in both f and other.
+func (f FeatureSet) Intersect(other FeatureSet) FeatureSet {
+	for i, v := range other.s {
+		f.s[i] &= v
+	}
+	return f
+}
+
+// Difference returns features present in f, but not in other.
+func (f FeatureSet) Difference(other FeatureSet) FeatureSet {
+	for i, v := range other.s {
+		f.s[i] &^= v
+	}
+	return f
+}
+
+// Contains returns whether the feature is in the set.
+func (f FeatureSet) Contains(id FeatureID) bool {
+	if id < firstID || id >= lastID {
+		return false
+	}
+	return f.s.inSet(id)
+}
+
+// ContainsAll returns whether all features in other are in the set.
+func (f FeatureSet) ContainsAll(other FeatureSet) bool {
+	return f.s.hasSet(other.s)
+}
+
+// Len returns the number of features in the set.
+func (f FeatureSet) Len() int {
+	return f.s.nEnabled()
+}
+
+// IDs returns the features in the set, ordered by FeatureID.
+func (f FeatureSet) IDs() []FeatureID {
+	ids := make([]FeatureID, 0, f.Len())
+	f.each(func(id FeatureID) bool {
+		ids = append(ids, id)
+		return true
+	})
+	return ids
+}
+
+// String returns the names of the features in the set, separated by commas.
+func (f FeatureSet) String() string {
+	var sb strings.Builder
+	f.each(func(id FeatureID) bool {
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(id.String())
+		return true
+	})
+	return sb.String()
+}
+
+// MarshalText returns the same as String.
+func (f FeatureSet) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText parses the set in the format returned by String.
+func (f *FeatureSet) UnmarshalText(b []byte) error {
+	v, err := ParseFeatureSet(string(b))
+	if err != nil {
+		return err
+	}
+	*f = v
+	return nil
+}
+
+// ParseFeatureSet parses comma separated feature names, as returned by String.
+// Names are not case sensitive and surrounding spaces are ignored.
+// An error is returned if a feature is not recognized.
+func ParseFeatureSet(s string) (FeatureSet, error) {
+	var f FeatureSet
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id := ParseFeature(name)
+		if id == UNKNOWN {
+			return FeatureSet{}, fmt.Errorf("cpuid: unknown feature %q", name)
+		}
+		f.s.set(id)
+	}
+	return f, nil
+}
+
+// each calls fn for each feature in the set, in order, until fn returns false.
+func (f FeatureSet) each(fn func(id FeatureID) bool) {
+	for i, v := range f.s {
+		for v != 0 {
+			id := FeatureID(i<<flagBitsLog2 + bits.TrailingZeros64(uint64(v)))
+			v &= v - 1
+			if !fn(id) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,15 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+//go:build go1.23
+// +build go1.23
+
+package cpuid
+
+import "iter"
+
+// All returns an iterator over the features in the set, ordered by FeatureID.
+func (f FeatureSet) All() iter.Seq[FeatureID] {
+	return func(yield func(FeatureID) bool) {
+		f.each(yield)
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+//go:build go1.23
+// +build go1.23
+
+package cpuid
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFeatureSetAll(t *testing.T) {
+	f := CPU.Features()
+	if got := slices.Collect(f.All()); !slices.Equal(got, f.IDs()) {
+		t.Errorf("got %v, want %v", got, f.IDs())
+	}
+	n := 0
+	for range NewFeatureSet(SSE, SSE2, AVX).All() {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Error("iteration did not stop")
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// FlagRegistrar registers flags.
+// *flag.FlagSet implements it, and adapters can be written for other flag libraries.
+// If the registrar also has a Var(flag.Value, name, usage string) method,
+// it will be used for flags with custom values.
+type FlagRegistrar interface {
+	StringVar(p *string, name, value, usage string)
+	BoolVar(p *bool, name string, value bool, usage string)
+	IntVar(p *int, name string, value int, usage string)
+}
+
+// cpuFlags contains the values of the flags registered by FlagsOn.
+var cpuFlags struct {
+	disable, enable, profile string
+	dispatch                 string
+	maxLevel                 int
+	arm                      bool
+	features                 featuresFlag
+}
+
+// featuresFlag is the value of the cpu.features flag.
+// It can be used as a bool flag, or set to "json".
+type featuresFlag string
+
+func (f *featuresFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return string(*f)
+}
+
+func (f *featuresFlag) Set(s string) error {
+	switch strings.ToLower(s) {
+	case "true", "1":
+		*f = "true"
+	case "false", "0", "":
+		*f = ""
+	case "json":
+		*f = "json"
+	default:
+		return fmt.Errorf("invalid value %q, must be true, false or json", s)
+	}
+	return nil
+}
+
+func (f *featuresFlag) IsBoolFlag() bool { return true }
+
+// FlagsOn will register the cpuid flags on fs.
+// This must be called *before* fs.Parse AND
+// Detect must be called after the flags have been parsed.
+func FlagsOn(fs *flag.FlagSet) {
+	FlagsWith(fs)
+}
+
+// FlagsWith will register the cpuid flags using r.
+// This can be used with flag libraries other than the standard library.
+// Detect must be called after the flags have been parsed.
+//
+// The flags are:
+//
+//	cpu.disable   disable cpu features; comma separated list
+//	cpu.enable    enable cpu features if prerequisites are present; comma separated list
+//	cpu.maxlevel  limit features to an x86-64 microarchitecture level (1-4)
+//	cpu.profile   limit features to a profile, like "x86-64-v3" or "haswell"
+//	cpu.dispatch  select Dispatch implementations; comma separated list of dispatch=implementation
+//	cpu.features  list cpu features and exit; "json" lists all information as JSON
+//	cpu.arm       allow ARM features to be detected; can potentially crash
+func FlagsWith(r FlagRegistrar) {
+	r.StringVar(&cpuFlags.disable, "cpu.disable", "", "disable cpu features; comma separated list")
+	r.StringVar(&cpuFlags.enable, "cpu.enable", "", "enable cpu features if prerequisites are present; comma separated list")
+	r.IntVar(&cpuFlags.maxLevel, "cpu.maxlevel", 0, "limit features to an x86-64 microarchitecture level (1-4)")
+	r.StringVar(&cpuFlags.profile, "cpu.profile", "", "limit features to a profile, like \"x86-64-v3\" or \"haswell\"")
+	r.StringVar(&cpuFlags.dispatch, "cpu.dispatch", "", "select Dispatch implementations; comma separated list of dispatch=implementation")
+	const featuresUsage = "lists cpu features and exits; use -cpu.features=json for all information as JSON"
+	if v, ok := r.(interface {
+		Var(value flag.Value, name, usage string)
+	}); ok {
+		v.Var(&cpuFlags.features, "cpu.features", featuresUsage)
+	} else {
+		r.StringVar((*string)(&cpuFlags.features), "cpu.features", "", featuresUsage)
+	}
+	r.BoolVar(&cpuFlags.arm, "cpu.arm", false, "allow ARM features to be detected; can potentially crash")
+}
+
+// profileLevel returns the x86-64 microarchitecture level of a profile name.
+func profileLevel(name string) int {
+	switch strings.ToLower(name) {
+	case "x86-64", "x86-64-v1":
+		return 1
+	case "x86-64-v2":
+		return 2
+	case "x86-64-v3":
+		return 3
+	case "x86-64-v4":
+		return 4
+	}
+	return 0
+}
+
+// applyFlags applies the flag values to c.
+// If cpu.features is set, the features are printed and the program exits.
+func applyFlags(c *CPUInfo) {
+	f := &cpuFlags
+	if f.maxLevel != 0 {
+		if levelFeatures(f.maxLevel) == nil {
+			flagWarn("cpu.maxlevel", "invalid level %d", f.maxLevel)
+		} else {
+			c.LimitToLevel(f.maxLevel)
+		}
+	}
+	if f.profile != "" {
+		if level := profileLevel(f.profile); level > 0 {
+			// Keeps SYSCALL, so X64Level is kept.
+			c.LimitToLevel(level)
+		} else if p, ok := Profile(f.profile); ok {
+			c.LimitTo(p)
+		} else {
+			flagWarn("cpu.profile", "unknown profile %q", f.profile)
+		}
+	}
+	if f.disable != "" {
+		c.Disable(parseFeatureList("-cpu.disable", f.disable)...)
+	}
+	if f.enable != "" {
+		ids := parseFeatureList("-cpu.enable", f.enable)
+		if !c.Enable(ids...) {
+			flagWarn("cpu.enable", "missing prerequisites %v", c.MissingPrerequisites(ids...))
+		}
+	}
+	if f.features != "" {
+		// Values from registrars without Var are not validated.
+		mode := f.features
+		if err := mode.Set(string(f.features)); err != nil {
+			flagWarn("cpu.features", "%v", err)
+		} else if mode != "" {
+			if err := printFeatures(os.Stdout, c, string(mode)); err != nil {
+				fmt.Fprintln(os.Stderr, "cpuid:", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		}
+	}
+}
+
+// printFeatures writes the features of c as a list, or all information as JSON if format is "json".
+func printFeatures(w io.Writer, c *CPUInfo, format string) error {
+	if strings.EqualFold(format, "json") {
+		b, err := json.MarshalIndent(c, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	}
+	_, err := fmt.Fprintln(w, "cpu features:", strings.Join(c.FeatureSet(), ","))
+	return err
+}
+
+func flagWarn(name, format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "cpuid: -%s: %s\n", name, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,508 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+//go:build ignore
+// +build ignore
+
+// gen_featureinfo generates featureinfo_table.go.
+//
+// Descriptions and architectures are read from the FeatureID constants in cpuid.go.
+// Origins are found by looking at how the detection code sets each feature.
+// Categories are listed below.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"math/bits"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// categories of features. Features not listed are "system" features.
+var categories = map[string][]string{
+	"CategorySIMD": {
+		"AMD3DNOW", "AMD3DNOWEXT", "AMXBF16", "AMXFP16", "AMXINT8", "AMXFP8", "AMXTILE", "AMXTF32", "AMXCOMPLEX", "AMXTRANSPOSE",
+		"AVX", "AVX10", "AVX10_128", "AVX10_256", "AVX10_512", "AVX2", "AVX512BF16", "AVX512BITALG", "AVX512BMM", "AVX512BW",
+		"AVX512CD", "AVX512DQ", "AVX512ER", "AVX512F", "AVX512FP16", "AVX512IFMA", "AVX512PF", "AVX512VBMI", "AVX512VBMI2",
+		"AVX512VL", "AVX512VNNI", "AVX512VP2INTERSECT", "AVX512VPOPCNTDQ", "AVXIFMA", "AVXNECONVERT", "AVXSLOW", "AVXVNNI",
+		"AVXVNNIINT8", "AVXVNNIINT16", "F16C", "FMA3", "FMA4", "FP128", "FP256", "GFNI", "MMX", "MMXEXT", "MOVU",
+		"SSE", "SSE2", "SSE3", "SSE4", "SSE42", "SSE4A", "SSSE3", "X87", "XOP",
+		"ASIMD", "ASIMDDP", "ASIMDHP", "ASIMDRDM", "FCMA", "FHM", "FP", "FPHP", "JSCVT", "SVE",
+	},
+	"CategoryCrypto": {
+		"AESNI", "CLMUL", "KEYLOCKER", "KEYLOCKERW", "RDRAND", "RDSEED", "SHA", "SM3_X86", "SM4_X86", "VAES", "VPCLMULQDQ",
+		"AESARM", "PMULL", "RNDR", "SHA1", "SHA2", "SHA3", "SHA512", "SM3", "SM4",
+	},
+	"CategorySecurity": {
+		"BHI_CTRL", "CETIBT", "CETSS", "FLUSH_L1D", "IA32_ARCH_CAP", "IBPB", "IBPB_BRTYPE", "IBRS", "IBRS_PREFERRED",
+		"IBRS_PROVIDES_SMP", "IDPRED_CTRL", "MCDT_NO", "MD_CLEAR", "MPX", "NX", "PCONFIG", "PSFD", "RRSBA_CTRL",
+		"RTM_ALWAYS_ABORT", "SBPB", "SGX", "SGXLC", "SGXPQC", "SME", "SME_COHERENT", "SPEC_CTRL_SSBD", "SRBDS_CTRL",
+		"SRSO_MSR_FIX", "SRSO_NO", "SRSO_USER_KERNEL_NO", "STIBP", "STIBP_ALWAYSON", "TME", "TSA_L1_NO", "TSA_SQ_NO",
+		"TSA_VERW_CLEAR",
+		"GPA",
+	},
+	"CategoryVirtualization": {
+		"HYPERVISOR", "LBRVIRT", "NRIPS", "SEV", "SEV_64BIT", "SEV_ALTERNATIVE", "SEV_DEBUGSWAP", "SEV_ES",
+		"SEV_RESTRICTED", "SEV_SNP", "SVM", "SVMDA", "SVMFBASID", "SVML", "SVMNP", "SVMPF", "SVMPFT", "TDX_GUEST",
+		"TLB_FLUSH_NESTED", "TSCRATEMSR", "VMCBCLEAN", "VMPL", "VMSA_REGPROT", "VMX", "VTE",
+	},
+	"CategoryPMU": {
+		"IBS", "IBSBRNTRGT", "IBSFETCHSAM", "IBSFFV", "IBSOPCNT", "IBSOPCNTEXT", "IBSOPSAM", "IBSRDWROPCNT",
+		"IBSRIPINVALIDCHK", "IBS_FETCH_CTLX", "IBS_OPDATA4", "IBS_OPFUSE", "IBS_PREVENTHOST", "IBS_ZEN4", "MSRIRC",
+		"PMU_FIXEDCOUNTER_CYCLES", "PMU_FIXEDCOUNTER_REFCYCLES", "PMU_FIXEDCOUNTER_INSTRUCTIONS", "PMU_FIXEDCOUNTER_TOPDOWN_SLOTS",
+	},
+}
+
+// overrides contains origins for features where the detection
+// checks several bits at once.
+var overrides = map[string][]origin{
+	"AVX":        {{leaf: 1, cpuid: true, reg: "ECX", bit: 28, width: 1}},
+	"FMA3":       {{leaf: 1, cpuid: true, reg: "ECX", bit: 12, width: 1}},
+	"KEYLOCKERW": {{leaf: 0x19, cpuid: true, reg: "EBX", bit: 2, width: 1}},
+}
+
+// idRegisters contains the ARM system registers returned by functions.
+var idRegisters = map[string][]string{
+	"getProcFeatures":   {"ID_AA64PFR0_EL1"},
+	"getInstAttributes": {"ID_AA64ISAR0_EL1", "ID_AA64ISAR1_EL1"},
+}
+
+type origin struct {
+	leaf, subleaf uint32
+	cpuid         bool
+	reg           string
+	bit, width    int
+}
+
+type feature struct {
+	name, desc string
+	arch       string
+	category   string
+	origins    []origin
+}
+
+var (
+	fset     = token.NewFileSet()
+	features = map[string]*feature{}
+	consts   = map[string]uint64{}
+)
+
+func main() {
+	files := map[string]*ast.File{}
+	for _, name := range []string{"cpuid.go", "detect_arm64.go", "os_linux_arm64.go"} {
+		f, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			log.Fatal(err)
+		}
+		files[name] = f
+		collectConsts(f.Decls)
+	}
+	order := readFeatures(files["cpuid.go"])
+	for name, list := range categories {
+		for _, f := range list {
+			if features[f] == nil {
+				log.Fatalf("unknown feature %s in %s", f, name)
+			}
+			features[f].category = name
+		}
+	}
+
+	for _, fn := range []string{"support", "parseLeaf0AH"} {
+		walkFunc(files["cpuid.go"], fn)
+	}
+	walkFunc(files["detect_arm64.go"], "addInfo")
+	walkFunc(files["os_linux_arm64.go"], "detectOS")
+
+	for name, o := range overrides {
+		features[name].origins = o
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`// Code generated by "go run gen_featureinfo.go"; DO NOT EDIT.
+
+package cpuid
+
+var featureInfos = [lastID]featureInfo{
+`)
+	for _, name := range order {
+		f := features[name]
+		cat := f.category
+		if cat == "" {
+			cat = "CategorySystem"
+		}
+		fmt.Fprintf(&buf, "%s: {desc: %q, arch: %s, category: %s", name, f.desc, f.arch, cat)
+		if len(f.origins) > 0 {
+			buf.WriteString(", origins: []FeatureOrigin{")
+			for _, o := range f.origins {
+				if o.cpuid {
+					fmt.Fprintf(&buf, "{Leaf: %#x, Subleaf: %d, Register: %q, Bit: %d, Width: %d},", o.leaf, o.subleaf, o.reg, o.bit, o.width)
+				} else {
+					fmt.Fprintf(&buf, "{Register: %q, Bit: %d, Width: %d},", o.reg, o.bit, o.width)
+				}
+			}
+			buf.WriteString("}")
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err, "\n", buf.String())
+	}
+	if err := os.WriteFile("featureinfo_table.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readFeatures reads the FeatureID constants and returns them in order.
+func readFeatures(f *ast.File) (order []string) {
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.CONST || !declares(gd, "ADX") {
+			continue
+		}
+		arch := "ArchX86"
+		for _, spec := range gd.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if vs.Doc != nil {
+				switch doc := vs.Doc.Text(); {
+				case strings.HasPrefix(doc, "ARM"):
+					arch = "ArchARM64"
+				case strings.HasPrefix(doc, "x86"), strings.HasPrefix(doc, "PMU"):
+					arch = "ArchX86"
+				}
+			}
+			name := vs.Names[0].Name
+			switch name {
+			case "UNKNOWN", "lastID", "firstID":
+				continue
+			}
+			desc := ""
+			if vs.Comment != nil {
+				desc = strings.Join(strings.Fields(vs.Comment.Text()), " ")
+			}
+			features[name] = &feature{name: name, desc: desc, arch: arch}
+			order = append(order, name)
+		}
+	}
+	if len(order) == 0 {
+		log.Fatal("no features found")
+	}
+	return order
+}
+
+func declares(gd *ast.GenDecl, name string) bool {
+	for _, spec := range gd.Specs {
+		if vs, ok := spec.(*ast.ValueSpec); ok {
+			for _, n := range vs.Names {
+				if n.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// collectConsts adds all constants that can be evaluated.
+func collectConsts(decls []ast.Decl) {
+	for _, decl := range decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, n := range vs.Names {
+				if i < len(vs.Values) {
+					if v, ok := eval(vs.Values[i]); ok {
+						consts[n.Name] = v
+					}
+				}
+			}
+		}
+	}
+}
+
+// eval evaluates a constant integer expression.
+func eval(e ast.Expr) (uint64, bool) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.INT {
+			return 0, false
+		}
+		v, err := strconv.ParseUint(e.Value, 0, 64)
+		return v, err == nil
+	case *ast.ParenExpr:
+		return eval(e.X)
+	case *ast.Ident:
+		v, ok := consts[e.Name]
+		return v, ok
+	case *ast.BinaryExpr:
+		x, ok1 := eval(e.X)
+		y, ok2 := eval(e.Y)
+		if !ok1 || !ok2 {
+			return 0, false
+		}
+		switch e.Op {
+		case token.SHL:
+			return x << y, true
+		case token.OR:
+			return x | y, true
+		case token.AND:
+			return x & y, true
+		}
+	}
+	return 0, false
+}
+
+// walkFunc finds all features set in the function.
+func walkFunc(f *ast.File, name string) {
+	for _, decl := range f.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Name.Name != name || fd.Recv != nil {
+			continue
+		}
+		vars := map[string]origin{}
+		if name == "parseLeaf0AH" {
+			// Registers of leaf 0xA are supplied as parameters.
+			for _, reg := range []string{"EAX", "EBX", "ECX", "EDX"} {
+				vars[strings.ToLower(reg)] = origin{leaf: 0xa, cpuid: true, reg: reg, width: 32}
+			}
+		}
+		walkBlock(fd.Body.List, vars, nil)
+		return
+	}
+	log.Fatalf("function %s not found", name)
+}
+
+// walkBlock walks statements.
+// vars contains the registers assigned to variables.
+// cond is the condition of the innermost if statement.
+func walkBlock(list []ast.Stmt, vars map[string]origin, cond ast.Expr) {
+	scope := make(map[string]origin, len(vars))
+	for k, v := range vars {
+		scope[k] = v
+	}
+	for _, stmt := range list {
+		walkStmt(stmt, scope, cond)
+	}
+}
+
+func walkStmt(stmt ast.Stmt, vars map[string]origin, cond ast.Expr) {
+	switch s := stmt.(type) {
+	case *ast.DeclStmt:
+		if gd, ok := s.Decl.(*ast.GenDecl); ok {
+			collectConsts([]ast.Decl{gd})
+		}
+	case *ast.AssignStmt:
+		assign(s, vars)
+	case *ast.ExprStmt:
+		if call, ok := s.X.(*ast.CallExpr); ok {
+			setCall(call, vars, cond)
+		}
+	case *ast.IfStmt:
+		if s.Init != nil {
+			walkStmt(s.Init, vars, cond)
+		}
+		walkBlock(s.Body.List, vars, s.Cond)
+		if s.Else != nil {
+			walkStmt(s.Else, vars, nil)
+		}
+	case *ast.BlockStmt:
+		walkBlock(s.List, vars, cond)
+	case *ast.SwitchStmt:
+		for _, c := range s.Body.List {
+			walkBlock(c.(*ast.CaseClause).Body, vars, cond)
+		}
+	}
+}
+
+// assign records variables assigned from CPUID or ID registers.
+func assign(s *ast.AssignStmt, vars map[string]origin) {
+	var regs []origin
+	if call, ok := s.Rhs[0].(*ast.CallExpr); ok && len(s.Rhs) == 1 {
+		regs = callRegisters(call)
+	}
+	for i, lhs := range s.Lhs {
+		id, ok := lhs.(*ast.Ident)
+		if !ok || id.Name == "_" {
+			continue
+		}
+		if i < len(regs) {
+			vars[id.Name] = regs[i]
+		} else {
+			delete(vars, id.Name)
+		}
+	}
+}
+
+// callRegisters returns the registers returned by a call.
+func callRegisters(call *ast.CallExpr) (regs []origin) {
+	switch fn := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		var leaf, sub uint64
+		ok := true
+		switch fn.Sel.Name {
+		case "CPUID":
+			leaf, ok = eval(call.Args[0])
+		case "CPUIDEX":
+			leaf, ok = eval(call.Args[0])
+			if ok {
+				sub, ok = eval(call.Args[1])
+			}
+		default:
+			return nil
+		}
+		if !ok {
+			log.Fatalf("%v: cannot evaluate leaf", fset.Position(call.Pos()))
+		}
+		for _, reg := range []string{"EAX", "EBX", "ECX", "EDX"} {
+			regs = append(regs, origin{leaf: uint32(leaf), subleaf: uint32(sub), cpuid: true, reg: reg, width: 32})
+		}
+	case *ast.Ident:
+		for _, reg := range idRegisters[fn.Name] {
+			regs = append(regs, origin{reg: reg, width: 64})
+		}
+	}
+	return regs
+}
+
+// setCall adds origins for features set by set and setIf calls.
+// If the condition of setIf does not test any register bits,
+// the condition of the enclosing if statement is used.
+func setCall(call *ast.CallExpr, vars map[string]origin, cond ast.Expr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || (sel.Sel.Name != "set" && sel.Sel.Name != "setIf") {
+		return
+	}
+	feats := call.Args
+	var o []origin
+	if sel.Sel.Name == "setIf" {
+		feats = call.Args[1:]
+		o = origins(call.Args[0], vars)
+	}
+	if len(o) == 0 && cond != nil {
+		o = origins(cond, vars)
+	}
+	addOrigins(feats, o, call)
+}
+
+func addOrigins(args []ast.Expr, o []origin, call *ast.CallExpr) {
+	if len(o) == 0 {
+		return
+	}
+	for _, arg := range args {
+		id, ok := arg.(*ast.Ident)
+		if !ok || features[id.Name] == nil {
+			continue
+		}
+		f := features[id.Name]
+		for _, o := range o {
+			if o.width > 1 && o.cpuid {
+				if _, ok := overrides[id.Name]; !ok {
+					log.Fatalf("%v: %s is set from several bits, add it to overrides", fset.Position(call.Pos()), id.Name)
+				}
+			}
+			dup := false
+			for _, existing := range f.origins {
+				dup = dup || existing == o
+			}
+			if !dup {
+				f.origins = append(f.origins, o)
+			}
+		}
+	}
+}
+
+// origins returns the register bits tested by the expression.
+func origins(e ast.Expr, vars map[string]origin) (res []origin) {
+	ast.Inspect(e, func(n ast.Node) bool {
+		be, ok := n.(*ast.BinaryExpr)
+		if !ok {
+			return true
+		}
+		// Handle "x&1<<n", which is parsed as "(x&1)<<n".
+		if be.Op == token.SHL {
+			if inner, ok := unparen(be.X).(*ast.BinaryExpr); ok && inner.Op == token.AND {
+				if o, ok := regField(inner.X, inner.Y, vars); ok {
+					if shift, ok := eval(be.Y); ok {
+						o.bit += int(shift)
+						res = append(res, o)
+						return false
+					}
+				}
+			}
+		}
+		if be.Op == token.AND {
+			if o, ok := regField(be.X, be.Y, vars); ok {
+				res = append(res, o)
+				return false
+			}
+		}
+		return true
+	})
+	ast.Inspect(e, func(n ast.Node) bool {
+		// isSet(hwcap, hwcap_X)
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 {
+			return true
+		}
+		if fn, ok := call.Fun.(*ast.Ident); ok && fn.Name == "isSet" {
+			c, ok := call.Args[1].(*ast.Ident)
+			v, ok2 := consts[c.Name]
+			if !ok || !ok2 {
+				log.Fatalf("%v: unknown hwcap", fset.Position(call.Pos()))
+			}
+			reg := strings.ToUpper(c.Name[:strings.IndexByte(c.Name, '_')])
+			res = append(res, origin{reg: reg, bit: bits.TrailingZeros64(v), width: 1})
+		}
+		return true
+	})
+	return res
+}
+
+// regField returns the origin of "reg & mask" or "(reg >> n) & mask".
+func regField(x, y ast.Expr, vars map[string]origin) (origin, bool) {
+	mask, ok := eval(y)
+	if !ok || mask == 0 {
+		return origin{}, false
+	}
+	shift := uint64(0)
+	x = unparen(x)
+	if be, ok := x.(*ast.BinaryExpr); ok && be.Op == token.SHR {
+		shift, ok = eval(be.Y)
+		if !ok {
+			return origin{}, false
+		}
+		x = unparen(be.X)
+	}
+	id, ok := x.(*ast.Ident)
+	if !ok {
+		return origin{}, false
+	}
+	o, ok := vars[id.Name]
+	if !ok {
+		return origin{}, false
+	}
+	mask <<= shift
+	o.bit = bits.TrailingZeros64(mask)
+	o.width = bits.Len64(mask) - o.bit
+	return o, true
+}
+
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.X
+	}
+}
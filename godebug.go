@@ -0,0 +1,226 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"golang.org/x/sys/cpu"
+)
+
+// godebugOption is a GODEBUG cpu option and the feature it controls.
+type godebugOption struct {
+	name       string
+	id         FeatureID
+	requiredOn string // GOARCH where the option cannot be disabled
+}
+
+// godebugX86 contains the GODEBUG cpu options of the Go runtime and golang.org/x/sys/cpu on x86.
+var godebugX86 = []godebugOption{
+	{name: "adx", id: ADX},
+	{name: "aes", id: AESNI},
+	{name: "avx", id: AVX},
+	{name: "avx2", id: AVX2},
+	{name: "avx512", id: AVX512F},
+	{name: "avx512f", id: AVX512F},
+	{name: "avx512cd", id: AVX512CD},
+	{name: "avx512er", id: AVX512ER},
+	{name: "avx512pf", id: AVX512PF},
+	{name: "avx512vl", id: AVX512VL},
+	{name: "avx512bw", id: AVX512BW},
+	{name: "avx512dq", id: AVX512DQ},
+	{name: "avx512ifma", id: AVX512IFMA},
+	{name: "avx512vbmi", id: AVX512VBMI},
+	{name: "avx512vpopcntdq", id: AVX512VPOPCNTDQ},
+	{name: "avx512vnni", id: AVX512VNNI},
+	{name: "avx512vbmi2", id: AVX512VBMI2},
+	{name: "avx512bitalg", id: AVX512BITALG},
+	{name: "avx512bf16", id: AVX512BF16},
+	{name: "amxtile", id: AMXTILE},
+	{name: "amxint8", id: AMXINT8},
+	{name: "amxbf16", id: AMXBF16},
+	{name: "avxifma", id: AVXIFMA},
+	{name: "avxvnni", id: AVXVNNI},
+	{name: "avxvnniint8", id: AVXVNNIINT8},
+	{name: "bmi1", id: BMI1},
+	{name: "bmi2", id: BMI2},
+	{name: "cx16", id: CX16},
+	{name: "erms", id: ERMS},
+	{name: "fma", id: FMA3},
+	{name: "fsrm", id: FSRM},
+	{name: "osxsave", id: OSXSAVE},
+	{name: "pclmulqdq", id: CLMUL},
+	{name: "popcnt", id: POPCNT},
+	{name: "rdrand", id: RDRAND},
+	{name: "rdseed", id: RDSEED},
+	{name: "rdtscp", id: RDTSCP},
+	{name: "sha", id: SHA},
+	{name: "sse2", id: SSE2, requiredOn: "amd64"},
+	{name: "sse3", id: SSE3},
+	{name: "sse41", id: SSE4},
+	{name: "sse42", id: SSE42},
+	{name: "ssse3", id: SSSE3},
+	{name: "vpclmulqdq", id: VPCLMULQDQ},
+}
+
+// godebugARM64 contains the GODEBUG cpu options of the Go runtime and golang.org/x/sys/cpu on arm64.
+var godebugARM64 = []godebugOption{
+	{name: "fp", id: FP, requiredOn: "arm64"},
+	{name: "asimd", id: ASIMD, requiredOn: "arm64"},
+	{name: "aes", id: AESARM},
+	{name: "asimddp", id: ASIMDDP},
+	{name: "asimdfhm", id: FHM},
+	{name: "asimdhp", id: ASIMDHP},
+	{name: "asimdrdm", id: ASIMDRDM},
+	{name: "asimrdm", id: ASIMDRDM}, // Name used by golang.org/x/sys/cpu
+	{name: "atomics", id: ATOMICS},
+	{name: "cpuid", id: ARMCPUID},
+	{name: "crc32", id: CRC32},
+	{name: "dcpop", id: DCPOP},
+	{name: "evstrm", id: EVTSTRM},
+	{name: "fcma", id: FCMA},
+	{name: "fphp", id: FPHP},
+	{name: "jscvt", id: JSCVT},
+	{name: "lrcpc", id: LRCPC},
+	{name: "pmull", id: PMULL},
+	{name: "sha1", id: SHA1},
+	{name: "sha2", id: SHA2},
+	{name: "sha3", id: SHA3},
+	{name: "sha512", id: SHA512},
+	{name: "sm3", id: SM3},
+	{name: "sm4", id: SM4},
+	{name: "sve", id: SVE},
+}
+
+// godebugOptions returns the GODEBUG cpu options for the architecture.
+func godebugOptions(goarch string) []godebugOption {
+	switch goarch {
+	case "amd64", "386":
+		return godebugX86
+	case "arm64":
+		return godebugARM64
+	}
+	return nil
+}
+
+// parseGODEBUG returns the features disabled by "cpu.<name>=off" options in a GODEBUG value.
+// Options are processed in order, so "cpu.all=off,cpu.avx2=on" only leaves AVX2 and its prerequisites.
+// Unknown options are ignored, since the runtime already reports these.
+func parseGODEBUG(godebug, goarch string) []FeatureID {
+	opts := godebugOptions(goarch)
+	// Value of each option: "on", "off" or empty when not specified.
+	state := make([]string, len(opts))
+	for _, field := range strings.Split(godebug, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok || !strings.HasPrefix(key, "cpu.") || (value != "on" && value != "off") {
+			continue
+		}
+		key = strings.TrimPrefix(key, "cpu.")
+		for i, o := range opts {
+			if key == "all" || key == o.name {
+				state[i] = value
+			}
+		}
+	}
+	var ids []FeatureID
+	for i, o := range opts {
+		if state[i] == "off" && o.requiredOn != goarch {
+			ids = append(ids, o.id)
+		}
+	}
+	// Options turned on keep their prerequisites.
+	for i, o := range opts {
+		if state[i] == "on" {
+			ids = removeFeatures(ids, featureRequires[o.id])
+		}
+	}
+	return ids
+}
+
+// removeFeatures returns ids without the features in remove.
+func removeFeatures(ids []FeatureID, remove flagSet) []FeatureID {
+	res := ids[:0]
+	for _, id := range ids {
+		if !remove.inSet(id) {
+			res = append(res, id)
+		}
+	}
+	return res
+}
+
+// applyGODEBUG disables features turned off in the GODEBUG environment variable.
+func applyGODEBUG(c *CPUInfo) {
+	if v := os.Getenv("GODEBUG"); strings.Contains(v, "cpu.") {
+		c.Disable(parseGODEBUG(v, runtime.GOARCH)...)
+	}
+}
+
+// RuntimeDiscrepancy is a feature where this package and the Go runtime disagree.
+type RuntimeDiscrepancy struct {
+	Feature FeatureID
+	CPUID   bool // Enabled in this package
+	Runtime bool // Enabled in the Go runtime
+}
+
+// String returns the discrepancy as "AVX2: cpuid=true, runtime=false".
+func (d RuntimeDiscrepancy) String() string {
+	return fmt.Sprintf("%v: cpuid=%v, runtime=%v", d.Feature, d.CPUID, d.Runtime)
+}
+
+// runtimeFeature is a feature and its value in golang.org/x/sys/cpu.
+type runtimeFeature struct {
+	id FeatureID
+	v  *bool
+}
+
+// runtimeFeatures returns the features of golang.org/x/sys/cpu for the architecture.
+func runtimeFeatures(goarch string) []runtimeFeature {
+	switch goarch {
+	case "amd64", "386":
+		x := &cpu.X86
+		return []runtimeFeature{
+			{ADX, &x.HasADX}, {AESNI, &x.HasAES}, {AVX, &x.HasAVX}, {AVX2, &x.HasAVX2},
+			{AVX512F, &x.HasAVX512F}, {AVX512CD, &x.HasAVX512CD}, {AVX512ER, &x.HasAVX512ER}, {AVX512PF, &x.HasAVX512PF},
+			{AVX512VL, &x.HasAVX512VL}, {AVX512BW, &x.HasAVX512BW}, {AVX512DQ, &x.HasAVX512DQ}, {AVX512IFMA, &x.HasAVX512IFMA},
+			{AVX512VBMI, &x.HasAVX512VBMI}, {AVX512VPOPCNTDQ, &x.HasAVX512VPOPCNTDQ}, {AVX512VNNI, &x.HasAVX512VNNI},
+			{AVX512VBMI2, &x.HasAVX512VBMI2}, {AVX512BITALG, &x.HasAVX512BITALG}, {AVX512BF16, &x.HasAVX512BF16},
+			{AMXTILE, &x.HasAMXTile}, {AMXINT8, &x.HasAMXInt8}, {AMXBF16, &x.HasAMXBF16},
+			{AVXIFMA, &x.HasAVXIFMA}, {AVXVNNI, &x.HasAVXVNNI}, {AVXVNNIINT8, &x.HasAVXVNNIInt8},
+			{BMI1, &x.HasBMI1}, {BMI2, &x.HasBMI2}, {CX16, &x.HasCX16}, {ERMS, &x.HasERMS}, {FMA3, &x.HasFMA},
+			{OSXSAVE, &x.HasOSXSAVE}, {CLMUL, &x.HasPCLMULQDQ}, {POPCNT, &x.HasPOPCNT}, {RDRAND, &x.HasRDRAND}, {RDSEED, &x.HasRDSEED},
+			{SSE2, &x.HasSSE2}, {SSE3, &x.HasSSE3}, {SSSE3, &x.HasSSSE3}, {SSE4, &x.HasSSE41}, {SSE42, &x.HasSSE42},
+		}
+	case "arm64":
+		a := &cpu.ARM64
+		return []runtimeFeature{
+			{FP, &a.HasFP}, {ASIMD, &a.HasASIMD}, {EVTSTRM, &a.HasEVTSTRM}, {AESARM, &a.HasAES}, {PMULL, &a.HasPMULL},
+			{SHA1, &a.HasSHA1}, {SHA2, &a.HasSHA2}, {SHA3, &a.HasSHA3}, {SHA512, &a.HasSHA512}, {CRC32, &a.HasCRC32},
+			{ATOMICS, &a.HasATOMICS}, {FPHP, &a.HasFPHP}, {ASIMDHP, &a.HasASIMDHP}, {ARMCPUID, &a.HasCPUID},
+			{ASIMDRDM, &a.HasASIMDRDM}, {JSCVT, &a.HasJSCVT}, {FCMA, &a.HasFCMA}, {LRCPC, &a.HasLRCPC},
+			{DCPOP, &a.HasDCPOP}, {SM3, &a.HasSM3}, {SM4, &a.HasSM4}, {ASIMDDP, &a.HasASIMDDP}, {SVE, &a.HasSVE},
+			{FHM, &a.HasASIMDFHM},
+		}
+	}
+	return nil
+}
+
+// RuntimeDiscrepancies compares the features of the current snapshot
+// with the features detected by the Go runtime and returns the differences.
+// The runtime features are read from golang.org/x/sys/cpu,
+// which uses the same detection and GODEBUG settings as the runtime.
+// Only features known by both are compared.
+// Features disabled in this package with Disable, flags or environment variables
+// will also be reported.
+func RuntimeDiscrepancies() []RuntimeDiscrepancy {
+	c := current.Load()
+	var res []RuntimeDiscrepancy
+	for _, f := range runtimeFeatures(runtime.GOARCH) {
+		if has := c.Has(f.id); has != *f.v {
+			res = append(res, RuntimeDiscrepancy{Feature: f.id, CPUID: has, Runtime: *f.v})
+		}
+	}
+	return res
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"sort"
+	"sync"
+)
+
+// leafRegs contains the registers of a single CPUID leaf and subleaf.
+type leafRegs struct {
+	op, sub uint32
+	regs    [4]uint32
+}
+
+// leafCache contains the leaves of a source.
+// The leaves are read the first time they are used,
+// so detection does not need to execute CPUID for every leaf.
+// This is a separate read from the detection, which may run on another CPU.
+type leafCache struct {
+	once               sync.Once
+	src                Source
+	maxFunc, maxExFunc uint32
+	leaves             []leafRegs
+}
+
+// get returns the leaves, reading them from the source on the first call.
+func (l *leafCache) get() []leafRegs {
+	if l == nil {
+		return nil
+	}
+	l.once.Do(func() {
+		l.leaves = readLeaves(l.src, l.maxFunc, l.maxExFunc)
+	})
+	return l.leaves
+}
+
+// Leaf returns the EAX, EBX, ECX and EDX registers of CPUID leaf op and subleaf.
+// All leaves are read from the source of the detection the first time Leaf or Dump is called.
+// This is a separate read, done after detection and possibly on another CPU,
+// so the leaves may not match the detected values if the CPU has changed,
+// for example after a VM live migration or on hybrid CPUs.
+// Leaves that were not read or contained no information will return all zeros.
+func (c CPUInfo) Leaf(op, subleaf uint32) (eax, ebx, ecx, edx uint32) {
+	leaves := c.leaves.get()
+	i := sort.Search(len(leaves), func(i int) bool {
+		l := leaves[i]
+		return l.op > op || (l.op == op && l.sub >= subleaf)
+	})
+	if i < len(leaves) && leaves[i].op == op && leaves[i].sub == subleaf {
+		r := leaves[i].regs
+		return r[0], r[1], r[2], r[3]
+	}
+	return 0, 0, 0, 0
+}
+
+// Dump will write all CPUID leaves of the detection source to w.
+// The leaves are read the first time Leaf or Dump is called, separately from the detection.
+// See Leaf.
+// The output uses the instlatx64 format of the files in testdata/cpuid_data.zip,
+// so it can be loaded with cpuidtest.ParseDump.
+// An error is returned if no leaves are available, for example on non-x86 platforms.
+func (c CPUInfo) Dump(w io.Writer) error {
+	leaves := c.leaves.get()
+	if len(leaves) == 0 {
+		return errors.New("cpuid: no CPUID leaves available")
+	}
+	bw := bufio.NewWriter(w)
+	for _, l := range leaves {
+		r := l.regs
+		fmt.Fprintf(bw, "CPUID %08X: %08X-%08X-%08X-%08X", l.op, r[0], r[1], r[2], r[3])
+		if hasSubleaves(l.op) {
+			fmt.Fprintf(bw, " [SL %02X]", l.sub)
+		}
+		if err := bw.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// readLeaves reads all valid leaves and subleaves from src.
+// Leaves where all registers are zero are not stored.
+func readLeaves(src Source, maxFunc, maxExFunc uint32) []leafRegs {
+	var dst []leafRegs
+	add := func(op, sub uint32) [4]uint32 {
+		a, b, c, d := src.CPUIDEX(op, sub)
+		r := [4]uint32{a, b, c, d}
+		if r != [4]uint32{} {
+			dst = append(dst, leafRegs{op: op, sub: sub, regs: r})
+		}
+		return r
+	}
+	// Limit ranges, in case we get garbage.
+	readRange := func(first, last uint32) {
+		if last < first || last-first > 0xff {
+			return
+		}
+		for op := first; op <= last; op++ {
+			readSubleaves(op, add)
+		}
+	}
+	readRange(0, maxFunc)
+	if _, _, ecx, _ := src.CPUID(1); maxFunc >= 1 && ecx&(1<<31) != 0 {
+		eax, _, _, _ := src.CPUID(0x40000000)
+		readRange(0x40000000, eax)
+	}
+	readRange(0x80000000, maxExFunc)
+	return dst
+}
+
+// hasSubleaves returns whether leaf op is indexed by ECX.
+func hasSubleaves(op uint32) bool {
+	switch op {
+	case 0x04, 0x07, 0x0B, 0x0D, 0x0F, 0x10, 0x12, 0x14, 0x17, 0x18, 0x1B, 0x1D, 0x1E, 0x1F, 0x20, 0x23, 0x24,
+		0x8000001D, 0x80000020, 0x80000026:
+		return true
+	}
+	return false
+}
+
+// readSubleaves will call add for all valid subleaves of op.
+// add returns the registers of the subleaf.
+func readSubleaves(op uint32, add func(op, sub uint32) [4]uint32) {
+	const eax, ebx, ecx, edx = 0, 1, 2, 3
+	r := add(op, 0)
+	// Subleaves are listed as bits in a 64 bit mask.
+	addMask := func(mask uint64) {
+		for mask != 0 {
+			sl := uint32(bits.TrailingZeros64(mask))
+			mask &= mask - 1
+			if sl > 0 {
+				add(op, sl)
+			}
+		}
+	}
+	// Subleaves until fn returns false.
+	addWhile := func(first, max uint32, fn func(r [4]uint32) bool) {
+		for sl := first; sl < max; sl++ {
+			if !fn(add(op, sl)) {
+				return
+			}
+		}
+	}
+	switch op {
+	case 0x04, 0x8000001D:
+		// Deterministic cache parameters. Cache type 0 is the last.
+		if r[eax]&0x1f != 0 {
+			addWhile(1, 32, func(r [4]uint32) bool { return r[eax]&0x1f != 0 })
+		}
+	case 0x07, 0x14, 0x17, 0x18, 0x1D, 0x20, 0x24:
+		// EAX contains the maximum subleaf.
+		for sl := uint32(1); sl <= r[eax] && sl < 64; sl++ {
+			add(op, sl)
+		}
+	case 0x0B, 0x1F, 0x80000026:
+		// Extended topology. Level type 0 is the last.
+		if (r[ecx]>>8)&0xff != 0 {
+			addWhile(1, 16, func(r [4]uint32) bool { return (r[ecx]>>8)&0xff != 0 })
+		}
+	case 0x0D:
+		// XSAVE components of XCR0 and IA32_XSS.
+		// Components 0 and 1 (x87 and SSE) are described by subleaf 0 and 1.
+		r1 := add(op, 1)
+		addMask((uint64(r[eax]) | uint64(r[edx])<<32 | uint64(r1[ecx]) | uint64(r1[edx])<<32) &^ 3)
+	case 0x0F:
+		// Resource types are listed in EDX.
+		addMask(uint64(r[edx]) & 0xe)
+	case 0x10:
+		// Resource types are listed in EBX.
+		addMask(uint64(r[ebx]) & 0xe)
+	case 0x12:
+		// SGX. Subleaf 2 and up are EPC sections, until type 0.
+		if r[eax] != 0 {
+			add(op, 1)
+			addWhile(2, 32, func(r [4]uint32) bool { return r[eax]&0xf != 0 })
+		}
+	case 0x1B:
+		// PCONFIG. Subleaf type 0 is the last.
+		if r[eax]&0xfff != 0 {
+			addWhile(1, 16, func(r [4]uint32) bool { return r[eax]&0xfff != 0 })
+		}
+	case 0x1E:
+		add(op, 1)
+	case 0x23:
+		// Valid subleaves are listed in EAX.
+		addMask(uint64(r[eax]))
+	case 0x80000020:
+		// Resource types are listed in EBX.
+		addMask(uint64(r[ebx]) & 0x3e)
+	}
+}
@@ -0,0 +1,419 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+)
+
+// MarshalText returns the name of the feature.
+func (i FeatureID) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText sets the feature from its name.
+// An error is returned if the name is not recognized.
+func (i *FeatureID) UnmarshalText(b []byte) error {
+	f := ParseFeature(string(b))
+	if f == UNKNOWN {
+		return fmt.Errorf("cpuid: unknown feature %q", b)
+	}
+	*i = f
+	return nil
+}
+
+// MarshalText returns the name of the vendor.
+func (i Vendor) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText sets the vendor from its name.
+// An error is returned if the name is not recognized.
+func (i *Vendor) UnmarshalText(b []byte) error {
+	for v := VendorUnknown; v < lastVendor; v++ {
+		if strings.EqualFold(v.String(), string(b)) {
+			*i = v
+			return nil
+		}
+	}
+	return fmt.Errorf("cpuid: unknown vendor %q", b)
+}
+
+// UnmarshalJSON sets the vendor from its name,
+// or from the number written by versions before MarshalText was added.
+func (i *Vendor) UnmarshalJSON(b []byte) error {
+	var n int
+	if err := json.Unmarshal(b, &n); err == nil {
+		if n < 0 || n >= int(lastVendor) {
+			return fmt.Errorf("cpuid: unknown vendor %d", n)
+		}
+		*i = Vendor(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return i.UnmarshalText([]byte(s))
+}
+
+// MarshalText returns the name of the cache type.
+func (t CacheType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText sets the cache type from its name.
+// An error is returned if the name is not recognized.
+func (t *CacheType) UnmarshalText(b []byte) error {
+	for v := CacheTypeUnknown; v <= CacheTypeUnified; v++ {
+		if strings.EqualFold(v.String(), string(b)) {
+			*t = v
+			return nil
+		}
+	}
+	return fmt.Errorf("cpuid: unknown cache type %q", b)
+}
+
+// MarshalText returns the name of the TLB type.
+func (t TLBType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText sets the TLB type from its name.
+// An error is returned if the name is not recognized.
+func (t *TLBType) UnmarshalText(b []byte) error {
+	for v := TLBTypeUnknown; v <= TLBTypeStore; v++ {
+		if strings.EqualFold(v.String(), string(b)) {
+			*t = v
+			return nil
+		}
+	}
+	return fmt.Errorf("cpuid: unknown TLB type %q", b)
+}
+
+// MarshalText returns the name of the topology type.
+func (t TopologyType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText sets the topology type from its name.
+// An error is returned if the name is not recognized.
+func (t *TopologyType) UnmarshalText(b []byte) error {
+	for v := TopologyUnknown; v <= TopologyPackage; v++ {
+		if strings.EqualFold(v.String(), string(b)) {
+			*t = v
+			return nil
+		}
+	}
+	return fmt.Errorf("cpuid: unknown topology type %q", b)
+}
+
+// cpuInfoJSON has the fields of CPUInfo, but not the methods.
+type cpuInfoJSON CPUInfo
+
+// MarshalJSON returns the exported fields of c as JSON,
+// with the detected features as "Features" and the microarchitecture level as "X64Level".
+// Raw CPUID leaves are not included. Use Dump to save these.
+func (c CPUInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		cpuInfoJSON
+		Features []string
+		X64Level int
+	}{
+		cpuInfoJSON: cpuInfoJSON(c),
+		Features:    c.FeatureSet(),
+		X64Level:    c.X64Level(),
+	})
+}
+
+// UnmarshalJSON will restore c from JSON produced by MarshalJSON.
+// Features that are not recognized are ignored,
+// so JSON from newer versions of the package can be read.
+func (c *CPUInfo) UnmarshalJSON(b []byte) error {
+	*c = CPUInfo{}
+	v := struct {
+		*cpuInfoJSON
+		Features []string
+	}{cpuInfoJSON: (*cpuInfoJSON)(c)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	for _, name := range v.Features {
+		if f := ParseFeature(name); f != UNKNOWN {
+			c.featureSet.set(f)
+		}
+	}
+	return nil
+}
+
+// binaryVersion is the version of the MarshalBinary encoding.
+// Version 2 added Variant and Revision, version 3 added Partial,
+// version 4 added Caches, version 5 added TLBs and version 6 added Topology.
+const binaryVersion = 6
+
+// MarshalBinary returns a compact binary encoding of the exported fields and features of c.
+// Features are stored using their stable wire codes,
+// so the encoding can be read by other versions of this package.
+// Raw CPUID leaves are not included.
+func (c CPUInfo) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 128)
+	b = append(b, binaryVersion, c.VendorID.code(), c.HypervisorVendorID.code())
+	for _, s := range []string{c.BrandName, c.VendorString, c.HypervisorVendorString} {
+		b = binary.AppendUvarint(b, uint64(len(s)))
+		b = append(b, s...)
+	}
+	for _, v := range []int64{int64(c.PhysicalCores), int64(c.ThreadsPerCore), int64(c.LogicalCores),
+		int64(c.Family), int64(c.Model), int64(c.Stepping), int64(c.CacheLine), c.Hz, c.BoostFreq,
+		int64(c.Cache.L1I), int64(c.Cache.L1D), int64(c.Cache.L2), int64(c.Cache.L3), int64(c.AVX10Level)} {
+		b = binary.AppendVarint(b, v)
+	}
+
+	// Features as sorted delta encoded codes.
+	codes := c.featureCodes()
+	b = binary.AppendUvarint(b, uint64(len(codes)))
+	prev := uint16(0)
+	for _, code := range codes {
+		b = binary.AppendUvarint(b, uint64(code-prev))
+		prev = code
+	}
+
+	b = append(b, boolBits(c.SGX.Available, c.SGX.LaunchControl, c.SGX.SGX1Supported, c.SGX.SGX2Supported))
+	b = binary.AppendVarint(b, c.SGX.MaxEnclaveSizeNot64)
+	b = binary.AppendVarint(b, c.SGX.MaxEnclaveSize64)
+	b = binary.AppendUvarint(b, uint64(len(c.SGX.EPCSections)))
+	for _, s := range c.SGX.EPCSections {
+		b = binary.AppendUvarint(b, s.BaseAddress)
+		b = binary.AppendUvarint(b, s.EPCSize)
+	}
+
+	m := c.AMDMemEncryption
+	b = append(b, boolBits(m.Available))
+	for _, v := range []uint32{m.CBitPossition, m.NumVMPL, m.PhysAddrReduction, m.NumEntryptedGuests, m.MinSevNoEsAsid} {
+		b = binary.AppendUvarint(b, uint64(v))
+	}
+
+	p := c.PMU
+	b = append(b, p.VersionID, p.NumGPCounters, p.GPPMCWidth, p.NumFixedPMC, p.FixedPMCWidth)
+	for _, v := range []uint32{p.RawEBX, p.RawEAX, p.RawEDX} {
+		b = binary.AppendUvarint(b, uint64(v))
+	}
+	b = binary.AppendVarint(b, int64(c.Variant))
+	b = binary.AppendVarint(b, int64(c.Revision))
+	b = append(b, boolBits(c.Partial))
+	b = binary.AppendUvarint(b, uint64(len(c.Caches)))
+	for _, ci := range c.Caches {
+		b = append(b, byte(ci.Level), byte(ci.Type), boolBits(ci.FullyAssociative, ci.Inclusive, ci.WriteBack, ci.ComplexIndexing))
+		for _, v := range []int{ci.Size, ci.Ways, ci.Sets, ci.LineSize, ci.Partitions, ci.SharedBy} {
+			b = binary.AppendUvarint(b, uint64(v))
+		}
+	}
+	b = binary.AppendUvarint(b, uint64(len(c.TLBs)))
+	for _, t := range c.TLBs {
+		b = append(b, byte(t.Level), byte(t.Type), byte(t.PageSizes), boolBits(t.FullyAssociative))
+		for _, v := range []int{t.Entries, t.Ways, t.SharedBy} {
+			b = binary.AppendUvarint(b, uint64(v))
+		}
+	}
+	b = binary.AppendUvarint(b, uint64(len(c.Topology.Levels)))
+	for _, l := range c.Topology.Levels {
+		b = append(b, byte(l.Type), byte(l.Shift))
+		b = binary.AppendUvarint(b, uint64(l.LogicalCPUs))
+	}
+	t := c.Topology
+	for _, v := range []int{int(t.X2APICID), t.NodeID, t.NodesPerPackage, t.LogicalCPUs, t.Packages} {
+		b = binary.AppendUvarint(b, uint64(v))
+	}
+	return b, nil
+}
+
+// UnmarshalBinary will restore c from data produced by MarshalBinary.
+// Features with unknown codes are ignored.
+func (c *CPUInfo) UnmarshalBinary(data []byte) error {
+	d := binaryDecoder{b: data}
+	version := d.byte()
+	if version < 1 || version > binaryVersion {
+		if d.err != nil {
+			return d.err
+		}
+		return fmt.Errorf("cpuid: unknown binary version %d", version)
+	}
+	var r CPUInfo
+	r.VendorID = vendorFromCode(d.byte())
+	r.HypervisorVendorID = vendorFromCode(d.byte())
+	r.BrandName, r.VendorString, r.HypervisorVendorString = d.string(), d.string(), d.string()
+	r.PhysicalCores, r.ThreadsPerCore, r.LogicalCores = int(d.varint()), int(d.varint()), int(d.varint())
+	r.Family, r.Model, r.Stepping, r.CacheLine = int(d.varint()), int(d.varint()), int(d.varint()), int(d.varint())
+	r.Hz, r.BoostFreq = d.varint(), d.varint()
+	r.Cache.L1I, r.Cache.L1D, r.Cache.L2, r.Cache.L3 = int(d.varint()), int(d.varint()), int(d.varint()), int(d.varint())
+	r.AVX10Level = uint8(d.varint())
+
+	code := uint64(0)
+	for n := d.uvarint(); n > 0 && d.err == nil; n-- {
+		code += d.uvarint()
+		if code <= math.MaxUint16 {
+			if f := FeatureFromCode(uint16(code)); f != UNKNOWN {
+				r.featureSet.set(f)
+			}
+		}
+	}
+
+	sgx := d.byte()
+	r.SGX.Available, r.SGX.LaunchControl = sgx&1 != 0, sgx&2 != 0
+	r.SGX.SGX1Supported, r.SGX.SGX2Supported = sgx&4 != 0, sgx&8 != 0
+	r.SGX.MaxEnclaveSizeNot64, r.SGX.MaxEnclaveSize64 = d.varint(), d.varint()
+	for n := d.uvarint(); n > 0 && d.err == nil; n-- {
+		r.SGX.EPCSections = append(r.SGX.EPCSections, SGXEPCSection{BaseAddress: d.uvarint(), EPCSize: d.uvarint()})
+	}
+
+	m := &r.AMDMemEncryption
+	m.Available = d.byte()&1 != 0
+	m.CBitPossition, m.NumVMPL, m.PhysAddrReduction = uint32(d.uvarint()), uint32(d.uvarint()), uint32(d.uvarint())
+	m.NumEntryptedGuests, m.MinSevNoEsAsid = uint32(d.uvarint()), uint32(d.uvarint())
+
+	p := &r.PMU
+	p.VersionID, p.NumGPCounters, p.GPPMCWidth, p.NumFixedPMC, p.FixedPMCWidth = d.byte(), d.byte(), d.byte(), d.byte(), d.byte()
+	p.RawEBX, p.RawEAX, p.RawEDX = uint32(d.uvarint()), uint32(d.uvarint()), uint32(d.uvarint())
+	if version >= 2 {
+		r.Variant, r.Revision = int(d.varint()), int(d.varint())
+	}
+	if version >= 3 {
+		r.Partial = d.byte()&1 != 0
+	}
+	if version >= 4 {
+		for n := d.uvarint(); n > 0 && d.err == nil; n-- {
+			ci := CacheInfo{Level: int(d.byte()), Type: CacheType(d.byte())}
+			flags := d.byte()
+			ci.FullyAssociative, ci.Inclusive, ci.WriteBack, ci.ComplexIndexing = flags&1 != 0, flags&2 != 0, flags&4 != 0, flags&8 != 0
+			ci.Size, ci.Ways, ci.Sets = int(d.uvarint()), int(d.uvarint()), int(d.uvarint())
+			ci.LineSize, ci.Partitions, ci.SharedBy = int(d.uvarint()), int(d.uvarint()), int(d.uvarint())
+			r.Caches = append(r.Caches, ci)
+		}
+	}
+	if version >= 5 {
+		for n := d.uvarint(); n > 0 && d.err == nil; n-- {
+			t := TLBInfo{Level: int(d.byte()), Type: TLBType(d.byte()), PageSizes: PageSizes(d.byte())}
+			t.FullyAssociative = d.byte()&1 != 0
+			t.Entries, t.Ways, t.SharedBy = int(d.uvarint()), int(d.uvarint()), int(d.uvarint())
+			r.TLBs = append(r.TLBs, t)
+		}
+	}
+	if version >= 6 {
+		t := &r.Topology
+		for n := d.uvarint(); n > 0 && d.err == nil; n-- {
+			l := TopologyLevel{Type: TopologyType(d.byte()), Shift: int(d.byte())}
+			l.LogicalCPUs = int(d.uvarint())
+			t.Levels = append(t.Levels, l)
+		}
+		t.X2APICID, t.NodeID, t.NodesPerPackage = uint32(d.uvarint()), int(d.uvarint()), int(d.uvarint())
+		t.LogicalCPUs, t.Packages = int(d.uvarint()), int(d.uvarint())
+	}
+	if d.err != nil {
+		return d.err
+	}
+	*c = r
+	return nil
+}
+
+// Fingerprint returns a stable hash of the vendor, family, model, stepping and enabled features.
+// The value will be the same between releases of this package,
+// unless detection of new features is added.
+func (c CPUInfo) Fingerprint() uint64 {
+	h := fnv.New64a()
+	var tmp [binary.MaxVarintLen64]byte
+	write := func(v uint64) {
+		h.Write(binary.AppendUvarint(tmp[:0], v))
+	}
+	write(uint64(c.VendorID.code()))
+	write(uint64(c.Family))
+	write(uint64(c.Model))
+	write(uint64(c.Stepping))
+	for _, code := range c.featureCodes() {
+		write(uint64(code))
+	}
+	return h.Sum64()
+}
+
+// featureCodes returns the sorted wire codes of the enabled features.
+func (c CPUInfo) featureCodes() []uint16 {
+	codes := make([]uint16, 0, c.featureSet.nEnabled())
+	for i := firstID; i < lastID; i++ {
+		if c.featureSet.inSet(i) && featureCodes[i] != 0 {
+			codes = append(codes, featureCodes[i])
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
+
+// boolBits returns the bools as bits.
+func boolBits(v ...bool) (b byte) {
+	for i, set := range v {
+		if set {
+			b |= 1 << i
+		}
+	}
+	return b
+}
+
+// binaryDecoder reads values written by MarshalBinary.
+// After the first error, all values will be zero.
+type binaryDecoder struct {
+	b   []byte
+	err error
+}
+
+var errBinaryShort = errors.New("cpuid: binary data too short")
+
+func (d *binaryDecoder) byte() byte {
+	if d.err != nil || len(d.b) == 0 {
+		d.err = errBinaryShort
+		return 0
+	}
+	v := d.b[0]
+	d.b = d.b[1:]
+	return v
+}
+
+func (d *binaryDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.err = errBinaryShort
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *binaryDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.b)
+	if n <= 0 {
+		d.err = errBinaryShort
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *binaryDecoder) string() string {
+	n := d.uvarint()
+	if d.err != nil || n > uint64(len(d.b)) {
+		d.err = errBinaryShort
+		return ""
+	}
+	s := string(d.b[:n])
+	d.b = d.b[n:]
+	return s
+}
@@ -0,0 +1,399 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import "fmt"
+
+// Generation is a core microarchitecture generation.
+// Generations of the same line of cores can be compared with AtLeast.
+type Generation uint16
+
+// Lines of cores. The generation ordinal is in the low byte.
+const (
+	genLineShift     = 8
+	genIntelCore     = 1 << genLineShift
+	genIntelAtom     = 2 << genLineShift
+	genIntelNetBurst = 3 << genLineShift
+	genAMD           = 4 << genLineShift
+	genAMDCat        = 5 << genLineShift
+)
+
+// GenerationUnknown is returned when the generation is not identified.
+const GenerationUnknown Generation = 0
+
+// Intel P6 and Core generations.
+const (
+	P5 Generation = genIntelCore + iota
+	P6
+	PentiumM
+	Core2
+	Penryn
+	Nehalem
+	Westmere
+	SandyBridge
+	IvyBridge
+	Haswell
+	Broadwell
+	Skylake
+	PalmCove
+	SunnyCove
+	WillowCove
+	GoldenCove
+	RaptorCove
+	RedwoodCove
+	LionCove
+	CougarCove
+)
+
+// Intel Atom generations.
+const (
+	Bonnell Generation = genIntelAtom + iota
+	Saltwell
+	Silvermont
+	Airmont
+	Goldmont
+	GoldmontPlus
+	Tremont
+	Gracemont
+	Crestmont
+	Skymont
+	Darkmont
+)
+
+// Intel NetBurst.
+const NetBurst Generation = genIntelNetBurst
+
+// AMD generations.
+const (
+	K5 Generation = genAMD + iota
+	K6
+	K7
+	K8
+	K10
+	Bulldozer
+	Piledriver
+	Steamroller
+	Excavator
+	Zen
+	ZenPlus
+	Zen2
+	Zen3
+	Zen4
+	Zen5
+)
+
+// AMD low power generations.
+const (
+	Bobcat Generation = genAMDCat + iota
+	Jaguar
+	Puma
+)
+
+var generationNames = map[Generation]string{
+	P5: "P5", P6: "P6", PentiumM: "Pentium M", Core2: "Core", Penryn: "Penryn", Nehalem: "Nehalem", Westmere: "Westmere",
+	SandyBridge: "Sandy Bridge", IvyBridge: "Ivy Bridge", Haswell: "Haswell", Broadwell: "Broadwell", Skylake: "Skylake",
+	PalmCove: "Palm Cove", SunnyCove: "Sunny Cove", WillowCove: "Willow Cove", GoldenCove: "Golden Cove",
+	RaptorCove: "Raptor Cove", RedwoodCove: "Redwood Cove", LionCove: "Lion Cove", CougarCove: "Cougar Cove",
+
+	Bonnell: "Bonnell", Saltwell: "Saltwell", Silvermont: "Silvermont", Airmont: "Airmont", Goldmont: "Goldmont",
+	GoldmontPlus: "Goldmont Plus", Tremont: "Tremont", Gracemont: "Gracemont", Crestmont: "Crestmont",
+	Skymont: "Skymont", Darkmont: "Darkmont",
+
+	NetBurst: "NetBurst",
+
+	K5: "K5", K6: "K6", K7: "K7", K8: "K8", K10: "K10", Bulldozer: "Bulldozer", Piledriver: "Piledriver", Steamroller: "Steamroller",
+	Excavator: "Excavator", Zen: "Zen", ZenPlus: "Zen+", Zen2: "Zen 2", Zen3: "Zen 3", Zen4: "Zen 4", Zen5: "Zen 5",
+
+	Bobcat: "Bobcat", Jaguar: "Jaguar", Puma: "Puma",
+}
+
+// String returns the name of the core generation.
+func (g Generation) String() string {
+	if s, ok := generationNames[g]; ok {
+		return s
+	}
+	if g == GenerationUnknown {
+		return "unknown"
+	}
+	return fmt.Sprintf("Generation(%d)", int(g))
+}
+
+// Microarch identifies the microarchitecture of a CPU.
+type Microarch struct {
+	Vendor     Vendor
+	Codename   string     // Product codename, like "Raptor Lake" or "Genoa"
+	Core       string     // Core microarchitecture, like "Raptor Cove" or "Zen 4"
+	Generation Generation // Core generation, GenerationUnknown if not identified
+}
+
+// AtLeast returns whether the core generation is g or newer.
+// Only generations of the same line of cores are compared,
+// so an Intel Atom core is not at least any Intel Core generation.
+// Hygon cores are compared as the AMD generation they are based on.
+func (m Microarch) AtLeast(g Generation) bool {
+	if m.Generation == GenerationUnknown || g == GenerationUnknown {
+		return false
+	}
+	return m.Generation>>genLineShift == g>>genLineShift && m.Generation >= g
+}
+
+// String returns the codename and core, like "Genoa (Zen 4)".
+func (m Microarch) String() string {
+	switch {
+	case m.Codename == "" && m.Core == "":
+		return "unknown"
+	case m.Codename == "" || m.Codename == m.Core:
+		return m.Core
+	case m.Core == "":
+		return m.Codename
+	}
+	return m.Codename + " (" + m.Core + ")"
+}
+
+// microarchEntry is an entry in the microarchitecture table.
+type microarchEntry struct {
+	vendor                 Vendor
+	family                 int
+	modelLo, modelHi       int
+	steppingLo, steppingHi int
+	codename               string
+	core                   string // Defaults to the name of gen.
+	gen                    Generation
+}
+
+// intelModel returns a table entry for an Intel family 6 model.
+func intelModel(model int, codename string, gen Generation) microarchEntry {
+	return microarchEntry{vendor: Intel, family: 6, modelLo: model, modelHi: model, steppingHi: 0xf, codename: codename, gen: gen}
+}
+
+// intelStepping returns a table entry for a range of steppings of an Intel family 6 model.
+func intelStepping(model, steppingLo, steppingHi int, codename string, gen Generation) microarchEntry {
+	e := intelModel(model, codename, gen)
+	e.steppingLo, e.steppingHi = steppingLo, steppingHi
+	return e
+}
+
+// amdModels returns a table entry for a range of AMD models.
+func amdModels(family, modelLo, modelHi int, codename string, gen Generation) microarchEntry {
+	return microarchEntry{vendor: AMD, family: family, modelLo: modelLo, modelHi: modelHi, steppingHi: 0xf, codename: codename, gen: gen}
+}
+
+// withCore returns e with a core name other than the generation name.
+func (e microarchEntry) withCore(core string) microarchEntry {
+	e.core = core
+	return e
+}
+
+// microarchTable is searched in order, so specific entries must be before ranges.
+// Sources are the Intel and AMD documentation and the Linux kernel family tables.
+var microarchTable = []microarchEntry{
+	// Intel P5
+	{vendor: Intel, family: 5, modelLo: 1, modelHi: 3, steppingHi: 0xf, codename: "Pentium", gen: P5},
+	{vendor: Intel, family: 5, modelLo: 4, modelHi: 8, steppingHi: 0xf, codename: "Pentium MMX", gen: P5},
+	{vendor: Intel, family: 5, modelLo: 9, modelHi: 0xA, steppingHi: 0xf, codename: "Quark"},
+
+	// Intel P6
+	intelModel(0x01, "Pentium Pro", P6),
+	intelModel(0x03, "Klamath", P6),
+	intelModel(0x05, "Deschutes", P6),
+	intelModel(0x06, "Dixon", P6),
+	intelModel(0x07, "Katmai", P6),
+	intelModel(0x08, "Coppermine", P6),
+	intelModel(0x0A, "Coppermine", P6),
+	intelModel(0x0B, "Tualatin", P6),
+	intelModel(0x09, "Banias", PentiumM),
+	intelModel(0x0D, "Dothan", PentiumM),
+	intelModel(0x0E, "Yonah", PentiumM),
+	intelModel(0x15, "Tolapai", PentiumM),
+
+	// Intel Core
+	intelModel(0x0F, "Merom", Core2),
+	intelModel(0x16, "Merom", Core2),
+	intelModel(0x17, "Penryn", Penryn),
+	intelModel(0x1D, "Dunnington", Penryn),
+	intelModel(0x1A, "Bloomfield", Nehalem),
+	intelModel(0x1E, "Lynnfield", Nehalem),
+	intelModel(0x1F, "Havendale", Nehalem),
+	intelModel(0x2E, "Beckton", Nehalem),
+	intelModel(0x25, "Clarkdale", Westmere),
+	intelModel(0x2C, "Gulftown", Westmere),
+	intelModel(0x2F, "Westmere-EX", Westmere),
+	intelModel(0x2A, "Sandy Bridge", SandyBridge),
+	intelModel(0x2D, "Sandy Bridge-E", SandyBridge),
+	intelModel(0x3A, "Ivy Bridge", IvyBridge),
+	intelModel(0x3E, "Ivy Bridge-E", IvyBridge),
+	intelModel(0x3C, "Haswell", Haswell),
+	intelModel(0x3F, "Haswell-E", Haswell),
+	intelModel(0x45, "Haswell-ULT", Haswell),
+	intelModel(0x46, "Crystal Well", Haswell),
+	intelModel(0x3D, "Broadwell", Broadwell),
+	intelModel(0x47, "Broadwell-H", Broadwell),
+	intelModel(0x4F, "Broadwell-E", Broadwell),
+	intelModel(0x56, "Broadwell-DE", Broadwell),
+	intelModel(0x4E, "Skylake", Skylake),
+	intelModel(0x5E, "Skylake", Skylake),
+	intelStepping(0x55, 0x0, 0x4, "Skylake-SP", Skylake),
+	intelStepping(0x55, 0x5, 0x7, "Cascade Lake", Skylake),
+	intelStepping(0x55, 0xA, 0xB, "Cooper Lake", Skylake),
+	intelModel(0x55, "Skylake-SP", Skylake),
+	intelStepping(0x8E, 0x0, 0x9, "Kaby Lake", Skylake),
+	intelStepping(0x8E, 0xA, 0xA, "Kaby Lake R", Skylake),
+	intelStepping(0x8E, 0xB, 0xB, "Whiskey Lake", Skylake),
+	intelStepping(0x8E, 0xC, 0xC, "Comet Lake", Skylake),
+	intelModel(0x8E, "Kaby Lake", Skylake),
+	intelStepping(0x9E, 0x0, 0x9, "Kaby Lake", Skylake),
+	intelModel(0x9E, "Coffee Lake", Skylake),
+	intelModel(0xA5, "Comet Lake", Skylake),
+	intelModel(0xA6, "Comet Lake", Skylake),
+	intelModel(0x66, "Cannon Lake", PalmCove),
+	intelModel(0x7D, "Ice Lake", SunnyCove),
+	intelModel(0x7E, "Ice Lake", SunnyCove),
+	intelModel(0x9D, "Ice Lake NNPI", SunnyCove),
+	intelModel(0x6A, "Ice Lake-SP", SunnyCove),
+	intelModel(0x6C, "Ice Lake-D", SunnyCove),
+	intelModel(0x8A, "Lakefield", SunnyCove),
+	intelModel(0xA7, "Rocket Lake", SunnyCove).withCore("Cypress Cove"),
+	intelModel(0x8C, "Tiger Lake", WillowCove),
+	intelModel(0x8D, "Tiger Lake", WillowCove),
+	intelModel(0x97, "Alder Lake", GoldenCove),
+	intelModel(0x9A, "Alder Lake", GoldenCove),
+	intelModel(0x8F, "Sapphire Rapids", GoldenCove),
+	intelModel(0xB7, "Raptor Lake", RaptorCove),
+	intelModel(0xBA, "Raptor Lake", RaptorCove),
+	intelModel(0xBF, "Raptor Lake", RaptorCove),
+	intelModel(0xCF, "Emerald Rapids", RaptorCove),
+	intelModel(0xAA, "Meteor Lake", RedwoodCove),
+	intelModel(0xAC, "Meteor Lake", RedwoodCove),
+	intelModel(0xB5, "Arrow Lake", RedwoodCove),
+	intelModel(0xAD, "Granite Rapids", RedwoodCove),
+	intelModel(0xAE, "Granite Rapids-D", RedwoodCove),
+	intelModel(0xC5, "Arrow Lake", LionCove),
+	intelModel(0xC6, "Arrow Lake", LionCove),
+	intelModel(0xBD, "Lunar Lake", LionCove),
+	intelModel(0xCC, "Panther Lake", CougarCove),
+
+	// Intel Atom
+	intelModel(0x1C, "Diamondville", Bonnell),
+	intelModel(0x26, "Lincroft", Bonnell),
+	intelModel(0x27, "Penwell", Saltwell),
+	intelModel(0x35, "Cloverview", Saltwell),
+	intelModel(0x36, "Cedarview", Saltwell),
+	intelModel(0x37, "Bay Trail", Silvermont),
+	intelModel(0x4A, "Merrifield", Silvermont),
+	intelModel(0x4D, "Avoton", Silvermont),
+	intelModel(0x5D, "SoFIA", Silvermont),
+	intelModel(0x4C, "Cherry Trail", Airmont),
+	intelModel(0x5A, "Moorefield", Airmont),
+	intelModel(0x75, "Lightning Mountain", Airmont),
+	intelModel(0x5C, "Apollo Lake", Goldmont),
+	intelModel(0x5F, "Denverton", Goldmont),
+	intelModel(0x7A, "Gemini Lake", GoldmontPlus),
+	intelModel(0x86, "Snow Ridge", Tremont),
+	intelModel(0x96, "Elkhart Lake", Tremont),
+	intelModel(0x9C, "Jasper Lake", Tremont),
+	intelModel(0xBE, "Alder Lake-N", Gracemont),
+	intelModel(0xAF, "Sierra Forest", Crestmont),
+	intelModel(0xB6, "Grand Ridge", Crestmont),
+	intelModel(0xDD, "Clearwater Forest", Darkmont),
+
+	// Intel Xeon Phi, which has no generation of its own.
+	intelModel(0x57, "Knights Landing", GenerationUnknown),
+	intelModel(0x85, "Knights Mill", GenerationUnknown),
+
+	// Intel NetBurst
+	{vendor: Intel, family: 0xF, modelLo: 0, modelHi: 1, steppingHi: 0xf, codename: "Willamette", gen: NetBurst},
+	{vendor: Intel, family: 0xF, modelLo: 2, modelHi: 2, steppingHi: 0xf, codename: "Northwood", gen: NetBurst},
+	{vendor: Intel, family: 0xF, modelLo: 3, modelHi: 4, steppingHi: 0xf, codename: "Prescott", gen: NetBurst},
+	{vendor: Intel, family: 0xF, modelLo: 6, modelHi: 6, steppingHi: 0xf, codename: "Cedar Mill", gen: NetBurst},
+
+	// AMD
+	amdModels(0x05, 0x00, 0x03, "K5", K5),
+	amdModels(0x05, 0x06, 0x07, "K6", K6),
+	amdModels(0x05, 0x08, 0x08, "K6-2", K6),
+	amdModels(0x05, 0x09, 0x09, "K6-III", K6),
+	amdModels(0x05, 0x0A, 0x0A, "Geode LX", GenerationUnknown),
+	amdModels(0x05, 0x0D, 0x0D, "K6-2+", K6),
+	amdModels(0x06, 0x01, 0x02, "Athlon", K7),
+	amdModels(0x06, 0x03, 0x03, "Spitfire", K7),
+	amdModels(0x06, 0x04, 0x04, "Thunderbird", K7),
+	amdModels(0x06, 0x06, 0x06, "Palomino", K7),
+	amdModels(0x06, 0x07, 0x07, "Morgan", K7),
+	amdModels(0x06, 0x08, 0x08, "Thoroughbred", K7),
+	amdModels(0x06, 0x0A, 0x0A, "Barton", K7),
+	amdModels(0x0F, 0x00, 0xFF, "K8", K8),
+	amdModels(0x10, 0x02, 0x02, "Barcelona", K10),
+	amdModels(0x10, 0x04, 0x04, "Shanghai", K10),
+	amdModels(0x10, 0x05, 0x05, "Propus", K10),
+	amdModels(0x10, 0x06, 0x06, "Regor", K10),
+	amdModels(0x10, 0x08, 0x08, "Istanbul", K10),
+	amdModels(0x10, 0x09, 0x09, "Magny-Cours", K10),
+	amdModels(0x10, 0x0A, 0x0A, "Thuban", K10),
+	amdModels(0x10, 0x00, 0xFF, "K10", K10),
+	amdModels(0x11, 0x00, 0xFF, "Griffin", K8),
+	amdModels(0x12, 0x00, 0xFF, "Llano", K10).withCore("Husky"),
+	amdModels(0x14, 0x00, 0xFF, "Brazos", Bobcat),
+	amdModels(0x15, 0x02, 0x02, "Vishera", Piledriver),
+	amdModels(0x15, 0x00, 0x0F, "Zambezi", Bulldozer),
+	amdModels(0x15, 0x10, 0x1F, "Trinity", Piledriver),
+	amdModels(0x15, 0x30, 0x3F, "Kaveri", Steamroller),
+	amdModels(0x15, 0x60, 0x6F, "Carrizo", Excavator),
+	amdModels(0x15, 0x70, 0x7F, "Stoney Ridge", Excavator),
+	amdModels(0x16, 0x00, 0x0F, "Kabini", Jaguar),
+	amdModels(0x16, 0x20, 0x2F, "Cato", Jaguar),
+	amdModels(0x16, 0x30, 0x3F, "Beema", Puma),
+	amdModels(0x17, 0x01, 0x01, "Naples", Zen),
+	amdModels(0x17, 0x08, 0x08, "Pinnacle Ridge", ZenPlus),
+	amdModels(0x17, 0x11, 0x11, "Raven Ridge", Zen),
+	amdModels(0x17, 0x18, 0x18, "Picasso", ZenPlus),
+	amdModels(0x17, 0x20, 0x20, "Dali", Zen),
+	amdModels(0x17, 0x00, 0x2F, "Zen", Zen),
+	amdModels(0x17, 0x31, 0x31, "Rome", Zen2),
+	amdModels(0x17, 0x60, 0x60, "Renoir", Zen2),
+	amdModels(0x17, 0x68, 0x68, "Lucienne", Zen2),
+	amdModels(0x17, 0x71, 0x71, "Matisse", Zen2),
+	amdModels(0x17, 0x90, 0x91, "Van Gogh", Zen2),
+	amdModels(0x17, 0xA0, 0xAF, "Mendocino", Zen2),
+	amdModels(0x17, 0x30, 0xFF, "Zen 2", Zen2),
+	amdModels(0x19, 0x00, 0x01, "Milan", Zen3),
+	amdModels(0x19, 0x08, 0x08, "Chagall", Zen3),
+	amdModels(0x19, 0x00, 0x0F, "Milan", Zen3),
+	amdModels(0x19, 0x10, 0x11, "Genoa", Zen4),
+	amdModels(0x19, 0x18, 0x18, "Storm Peak", Zen4),
+	amdModels(0x19, 0x10, 0x1F, "Genoa", Zen4),
+	amdModels(0x19, 0x20, 0x2F, "Vermeer", Zen3),
+	amdModels(0x19, 0x30, 0x3F, "Zen 3", Zen3),
+	amdModels(0x19, 0x40, 0x4F, "Rembrandt", Zen3).withCore("Zen 3+"),
+	amdModels(0x19, 0x50, 0x5F, "Cezanne", Zen3),
+	amdModels(0x19, 0x60, 0x6F, "Raphael", Zen4),
+	amdModels(0x19, 0x70, 0x7F, "Phoenix", Zen4),
+	amdModels(0x19, 0xA0, 0xAF, "Bergamo", Zen4).withCore("Zen 4c"),
+	amdModels(0x1A, 0x00, 0x0F, "Turin", Zen5),
+	amdModels(0x1A, 0x10, 0x1F, "Turin Dense", Zen5).withCore("Zen 5c"),
+	amdModels(0x1A, 0x20, 0x2F, "Strix Point", Zen5),
+	amdModels(0x1A, 0x40, 0x4F, "Granite Ridge", Zen5),
+	amdModels(0x1A, 0x60, 0x6F, "Krackan Point", Zen5),
+	amdModels(0x1A, 0x70, 0x7F, "Strix Halo", Zen5),
+
+	// Hygon
+	{vendor: Hygon, family: 0x18, modelHi: 0xFF, steppingHi: 0xf, codename: "Dhyana", core: "Dhyana", gen: Zen},
+}
+
+// Microarch returns the microarchitecture of the CPU,
+// identified by vendor, family, model and stepping.
+// Fields are empty if the CPU is not identified.
+func (c CPUInfo) Microarch() Microarch {
+	m := Microarch{Vendor: c.VendorID}
+	for _, e := range microarchTable {
+		if e.vendor != c.VendorID || e.family != c.Family ||
+			c.Model < e.modelLo || c.Model > e.modelHi ||
+			c.Stepping < e.steppingLo || c.Stepping > e.steppingHi {
+			continue
+		}
+		m.Codename, m.Core, m.Generation = e.codename, e.core, e.gen
+		if m.Core == "" && e.gen != GenerationUnknown {
+			m.Core = e.gen.String()
+		}
+		break
+	}
+	return m
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+// armImplementer is a vendor identified by the MIDR implementer code.
+type armImplementer struct {
+	vendor Vendor
+	name   string
+}
+
+// armImplementers contains the MIDR implementer codes.
+var armImplementers = map[uint8]armImplementer{
+	0x41: {ARM, "Arm Limited"},
+	0x42: {Broadcom, "Broadcom Corporation"},
+	0x43: {Cavium, "Cavium Inc"},
+	0x44: {DEC, "Digital Equipment Corporation"},
+	0x46: {Fujitsu, "Fujitsu Ltd"},
+	0x48: {HiSilicon, "HiSilicon Technologies Co. Ltd"},
+	0x49: {Infineon, "Infineon Technologies AG"},
+	0x4D: {Motorola, "Motorola or Freescale Semiconductor Inc"},
+	0x4E: {NVIDIA, "NVIDIA Corporation"},
+	0x50: {AMCC, "Applied Micro Circuits Corporation"},
+	0x51: {Qualcomm, "Qualcomm Inc"},
+	0x56: {Marvell, "Marvell International Ltd"},
+	0x61: {Apple, "Apple Inc"},
+	0x69: {Intel, "Intel Corporation"},
+	0x6D: {Microsoft, "Microsoft Corporation"},
+	0x70: {Phytium, "Phytium Technology Co. Ltd"},
+	0xC0: {Ampere, "Ampere Computing"},
+}
+
+// armPart is a part number of a vendor.
+type armPart struct {
+	vendor Vendor
+	part   int
+}
+
+// armCoreNames contains the core names of MIDR part numbers.
+// Many SoCs use cores licensed from Arm and report Arm as the implementer.
+// For example AWS Graviton 2, 3 and 4 report Neoverse N1, V1 and V2,
+// Ampere Altra reports Neoverse N1 and Azure Cobalt 100 reports Neoverse N2.
+var armCoreNames = map[armPart]string{
+	{ARM, 0xD02}: "Cortex-A34",
+	{ARM, 0xD03}: "Cortex-A53",
+	{ARM, 0xD04}: "Cortex-A35",
+	{ARM, 0xD05}: "Cortex-A55",
+	{ARM, 0xD06}: "Cortex-A65",
+	{ARM, 0xD07}: "Cortex-A57",
+	{ARM, 0xD08}: "Cortex-A72",
+	{ARM, 0xD09}: "Cortex-A73",
+	{ARM, 0xD0A}: "Cortex-A75",
+	{ARM, 0xD0B}: "Cortex-A76",
+	{ARM, 0xD0C}: "Neoverse N1",
+	{ARM, 0xD0D}: "Cortex-A77",
+	{ARM, 0xD0E}: "Cortex-A76AE",
+	{ARM, 0xD40}: "Neoverse V1",
+	{ARM, 0xD41}: "Cortex-A78",
+	{ARM, 0xD42}: "Cortex-A78AE",
+	{ARM, 0xD44}: "Cortex-X1",
+	{ARM, 0xD46}: "Cortex-A510",
+	{ARM, 0xD47}: "Cortex-A710",
+	{ARM, 0xD48}: "Cortex-X2",
+	{ARM, 0xD49}: "Neoverse N2",
+	{ARM, 0xD4A}: "Neoverse E1",
+	{ARM, 0xD4B}: "Cortex-A78C",
+	{ARM, 0xD4C}: "Cortex-X1C",
+	{ARM, 0xD4D}: "Cortex-A715",
+	{ARM, 0xD4E}: "Cortex-X3",
+	{ARM, 0xD4F}: "Neoverse V2",
+	{ARM, 0xD80}: "Cortex-A520",
+	{ARM, 0xD81}: "Cortex-A720",
+	{ARM, 0xD82}: "Cortex-X4",
+	{ARM, 0xD84}: "Neoverse V3",
+	{ARM, 0xD85}: "Cortex-X925",
+	{ARM, 0xD87}: "Cortex-A725",
+	{ARM, 0xD8E}: "Neoverse N3",
+
+	{Ampere, 0xAC3}: "AmpereOne",
+	{Ampere, 0xAC4}: "AmpereOne AC04",
+	{Ampere, 0xAC5}: "AmpereOne AC05",
+	{AMCC, 0x000}:   "X-Gene",
+
+	{Apple, 0x022}: "Icestorm",
+	{Apple, 0x023}: "Firestorm",
+	{Apple, 0x024}: "Icestorm",
+	{Apple, 0x025}: "Firestorm",
+	{Apple, 0x028}: "Icestorm",
+	{Apple, 0x029}: "Firestorm",
+	{Apple, 0x032}: "Blizzard",
+	{Apple, 0x033}: "Avalanche",
+	{Apple, 0x034}: "Blizzard",
+	{Apple, 0x035}: "Avalanche",
+	{Apple, 0x038}: "Blizzard",
+	{Apple, 0x039}: "Avalanche",
+
+	{Cavium, 0x0A1}:    "ThunderX",
+	{Cavium, 0x0AF}:    "ThunderX2",
+	{Fujitsu, 0x001}:   "A64FX",
+	{HiSilicon, 0xD01}: "TaiShan v110",
+	{HiSilicon, 0xD02}: "TaiShan v120",
+	{Microsoft, 0xD49}: "Azure Cobalt 100",
+	{NVIDIA, 0x003}:    "Denver 2",
+	{NVIDIA, 0x004}:    "Carmel",
+	{Phytium, 0x662}:   "FTC662",
+	{Phytium, 0x663}:   "FTC663",
+	{Phytium, 0x862}:   "FTC862",
+	{Qualcomm, 0x001}:  "Oryon",
+	{Qualcomm, 0x800}:  "Kryo 2xx Gold",
+	{Qualcomm, 0x801}:  "Kryo 2xx Silver",
+	{Qualcomm, 0x802}:  "Kryo 3xx Gold",
+	{Qualcomm, 0x803}:  "Kryo 3xx Silver",
+	{Qualcomm, 0x804}:  "Kryo 4xx Gold",
+	{Qualcomm, 0x805}:  "Kryo 4xx Silver",
+	{Qualcomm, 0xC00}:  "Falkor",
+}
+
+// appleCPUFamilies contains the core names of the hw.cpufamily values reported by macOS.
+var appleCPUFamilies = map[uint32]string{
+	0x1b588bb3: "Firestorm/Icestorm",
+	0xda33d83d: "Avalanche/Blizzard",
+	0x8765edea: "Everest/Sawtooth",
+}
+
+// decodeMIDR sets the vendor, family, model, variant and revision of c
+// from the MIDR_EL1 register.
+//
+// MIDR_EL1 - Main ID Register
+// https://developer.arm.com/docs/ddi0595/h/aarch64-system-registers/midr_el1
+//
+//	x--------------------------------------------------x
+//	| Name                         |  bits   | visible |
+//	|--------------------------------------------------|
+//	| Implementer                  | [31-24] |    y    |
+//	|--------------------------------------------------|
+//	| Variant                      | [23-20] |    y    |
+//	|--------------------------------------------------|
+//	| Architecture                 | [19-16] |    y    |
+//	|--------------------------------------------------|
+//	| PartNum                      | [15-4]  |    y    |
+//	|--------------------------------------------------|
+//	| Revision                     | [3-0]   |    y    |
+//	x--------------------------------------------------x
+func decodeMIDR(c *CPUInfo, midr uint64) {
+	if impl, ok := armImplementers[uint8(midr>>24)]; ok {
+		c.VendorString = impl.name
+		c.VendorID = impl.vendor
+	}
+	// Architecture 0b1111 means that features are identified in the ID_* registers.
+	c.Family = int(midr>>16) & 0xf
+	c.Model = int(midr>>4) & 0xfff
+	c.Variant = int(midr>>20) & 0xf
+	c.Revision = int(midr) & 0xf
+}
+
+// CoreName returns the name of the core, like "Neoverse N1" or "Golden Cove".
+// On ARM, the name is looked up from the MIDR implementer and part number.
+// On x86, the core of Microarch is returned.
+// An empty string is returned if the core is not known.
+func (c CPUInfo) CoreName() string {
+	if name, ok := armCoreNames[armPart{vendor: c.VendorID, part: c.Model}]; ok {
+		return name
+	}
+	if name, ok := appleCPUFamilies[uint32(c.Family)]; ok && c.VendorID == Apple {
+		return name
+	}
+	return c.Microarch().Core
+}
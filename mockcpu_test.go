@@ -1,222 +1,613 @@
-package cpuid
+package cpuid_test
 
 import (
 	"archive/zip"
+	"bytes"
+	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"math"
 	"path/filepath"
-	"sort"
+	"reflect"
 	"strings"
 	"testing"
+
+	. "github.com/klauspost/cpuid/v2"
+	"github.com/klauspost/cpuid/v2/cpuidtest"
 )
 
-type fakecpuid map[uint32][][]uint32
+func TestMocks(t *testing.T) {
+	forEachDump(t, func(name string, leaves cpuidtest.Leaves, CPU CPUInfo) {
+		t.Run(name, func(t *testing.T) {
+			maxFunc, _, _, _ := leaves.CPUID(0)
+			maxExFunc, _, _, _ := leaves.CPUID(0x80000000)
+			t.Log("Name:", CPU.BrandName)
+			t.Logf("Max Function:0x%x", maxFunc)
+			t.Logf("Max Extended Function:0x%x", maxExFunc)
+			t.Log("VendorString:", CPU.VendorString)
+			t.Log("VendorID:", CPU.VendorID)
+			t.Log("PhysicalCores:", CPU.PhysicalCores)
+			t.Log("ThreadsPerCore:", CPU.ThreadsPerCore)
+			t.Log("LogicalCores:", CPU.LogicalCores)
+			t.Log("Family", CPU.Family, "Model:", CPU.Model, "Stepping:", CPU.Stepping)
+			t.Log("Features:", strings.Join(CPU.FeatureSet(), ","))
+			t.Log("Microarchitecture level:", CPU.X64Level())
+			t.Log("Cacheline bytes:", CPU.CacheLine)
+			t.Log("L1 Instruction Cache:", CPU.Cache.L1I, "bytes")
+			t.Log("L1 Data Cache:", CPU.Cache.L1D, "bytes")
+			t.Log("L2 Cache:", CPU.Cache.L2, "bytes")
+			t.Log("L3 Cache:", CPU.Cache.L3, "bytes")
+			t.Log("Hz:", CPU.Hz, "Hz")
+			t.Log("Boost:", CPU.BoostFreq, "Hz")
+			if CPU.AVX10Level > 0 {
+				t.Log("AVX10 level:", CPU.AVX10Level)
+			}
+			if CPU.LogicalCores > 0 && CPU.PhysicalCores > 0 {
+				if CPU.LogicalCores != CPU.PhysicalCores*CPU.ThreadsPerCore {
+					t.Fatalf("Core count mismatch, LogicalCores (%d) != PhysicalCores (%d) * CPU.ThreadsPerCore (%d)",
+						CPU.LogicalCores, CPU.PhysicalCores, CPU.ThreadsPerCore)
+				}
+			}
+
+			// Leaves and subleaves that are not in the dump are returned as zero.
+			for _, l := range []cpuidtest.Leaf{{Op: maxFunc + 1}, {Op: maxExFunc + 1}, {Op: 4, Subleaf: 0x100}, {Op: 0xd, Subleaf: 0x100}} {
+				if _, ok := leaves[l]; ok {
+					continue
+				}
+				if eax, ebx, ecx, edx := leaves.CPUIDEX(l.Op, l.Subleaf); eax|ebx|ecx|edx != 0 {
+					t.Errorf("leaf %#x, subleaf %#x: got %08x %08x %08x %08x, want zero", l.Op, l.Subleaf, eax, ebx, ecx, edx)
+				}
+				if eax, ebx, ecx, edx := CPU.Leaf(l.Op, l.Subleaf); eax|ebx|ecx|edx != 0 {
+					t.Errorf("CPUInfo leaf %#x, subleaf %#x: got %08x %08x %08x %08x, want zero", l.Op, l.Subleaf, eax, ebx, ecx, edx)
+				}
+			}
+
+			c := CPU
+			if unsupported := c.DisableUnsupported(); unsupported.Len() > 0 {
+				t.Log("Unsupported:", unsupported)
+				if !CPU.Features().ContainsAll(unsupported) || c.Features().Intersect(unsupported).Len() > 0 {
+					t.Errorf("DisableUnsupported returned %v", unsupported)
+				}
+			}
+			for _, id := range c.Features().IDs() {
+				if missing := Implies(id).Difference(c.Features()); missing.Len() > 0 {
+					t.Errorf("%v kept without %v", id, missing)
+				}
+			}
+
+			if CPU.ThreadsPerCore > 1 && !CPU.Supports(HTT) {
+				t.Fatalf("Hyperthreading not detected")
+			}
+			if CPU.ThreadsPerCore == 1 && CPU.Supports(HTT) {
+				t.Fatalf("Hyperthreading detected, but only 1 Thread per core")
+			}
+
+			// Dumping the detected leaves and detecting again should give the same result.
+			var buf bytes.Buffer
+			if err := CPU.Dump(&buf); err != nil {
+				t.Fatal(err)
+			}
+			replay, err := cpuidtest.ParseDump(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := describe(CPU), describe(cpuidtest.DetectDump(replay)); want != got {
+				t.Fatalf("dump replay mismatch:\nwant: %s\ngot:  %s", want, got)
+			}
+
+			// JSON round trip.
+			b, err := json.Marshal(CPU)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fromJSON CPUInfo
+			if err := json.Unmarshal(b, &fromJSON); err != nil {
+				t.Fatal(err)
+			}
+			if want, got := describe(CPU), describe(fromJSON); want != got {
+				t.Fatalf("json mismatch:\nwant: %s\ngot:  %s", want, got)
+			}
+
+			// Binary round trip.
+			b, err = CPU.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fromBinary CPUInfo
+			if err := fromBinary.UnmarshalBinary(b); err != nil {
+				t.Fatal(err)
+			}
+			if want, got := describe(CPU), describe(fromBinary); want != got {
+				t.Fatalf("binary mismatch:\nwant: %s\ngot:  %s", want, got)
+			}
+			if fromBinary.Fingerprint() != CPU.Fingerprint() {
+				t.Fatal("fingerprint mismatch")
+			}
+			for i := range b {
+				if err := fromBinary.UnmarshalBinary(b[:i]); err == nil {
+					t.Fatalf("no error on truncated input, length %d of %d", i, len(b))
+				}
+			}
 
-type idfuncs struct {
-	cpuid   func(op uint32) (eax, ebx, ecx, edx uint32)
-	cpuidex func(op, op2 uint32) (eax, ebx, ecx, edx uint32)
-	xgetbv  func(index uint32) (eax, edx uint32)
+			// Limiting to a lower level should give that level.
+			for level := CPU.X64Level() - 1; level > 0; level-- {
+				limited := CPU
+				limited.LimitToLevel(level)
+				if got := limited.X64Level(); got != level {
+					t.Fatalf("LimitToLevel(%d) gave level %d", level, got)
+				}
+			}
+		})
+	})
 }
 
-func (f fakecpuid) String() string {
-	var out = make([]string, 0, len(f))
-	for key, val := range f {
-		for _, v := range val {
-			out = append(out, fmt.Sprintf("CPUID %08x: [%08x, %08x, %08x, %08x]", key, v[0], v[1], v[2], v[3]))
+func TestProfiles(t *testing.T) {
+	// Dumps with the profile they satisfy, and the next profile they don't.
+	tests := map[string][2]string{
+		"GenuineIntel00106A1_Nehalem_CPUID.txt":           {"nehalem", "sandybridge"},
+		"GenuineIntel00206A7_SandyBridge_CPUID.txt":       {"Sandy Bridge", "haswell"},
+		"GenuineIntel00306C3_Haswell_CPUID.txt":           {"haswell", "skylake"},
+		"GenuineIntel00506E3_Skylake_CPUID.txt":           {"skylake", "skylake-avx512"},
+		"GenuineIntel0050654_SkylakeX_CPUID.txt":          {"skylake-avx512", "icelake-server"},
+		"GenuineIntel00606A6_ICX_04_CPUID.txt":            {"icelake-server", "sapphirerapids"},
+		"GenuineIntel00806F8_SapphireRapids_05_CPUID.txt": {"sapphirerapids", "graniterapids"},
+		"AuthenticAMD0800F12_K17_Zen_CPUID.txt":           {"zen1", "znver2"},
+		"AuthenticAMD0830F10_K17_Rome_CPUID.txt":          {"znver2", "znver3"},
+		"AuthenticAMD0A00F11_K19_Milan_02_CPUID.txt":      {"znver3", "znver4"},
+		"AuthenticAMD0A10F11_K19_Genoa_01_CPUID.txt":      {"znver4", "znver5"},
+		"AuthenticAMD0B00F21_K20_Turin_01_CPUID.txt":      {"znver5", "sapphirerapids"},
+	}
+	found := 0
+	forEachDump(t, func(name string, _ cpuidtest.Leaves, c CPUInfo) {
+		test, ok := tests[name]
+		if !ok {
+			return
+		}
+		found++
+		has, _ := Profile(test[0])
+		if !c.Satisfies(has) {
+			t.Errorf("%s: does not satisfy %s, missing %v", name, test[0], has.Difference(c.Features()))
 		}
+		next, _ := Profile(test[1])
+		if c.Satisfies(next) {
+			t.Errorf("%s: satisfies %s", name, test[1])
+		}
+	})
+	if found != len(tests) {
+		t.Errorf("found %d of %d dumps", found, len(tests))
 	}
-	sorter := sort.StringSlice(out)
-	sort.Sort(&sorter)
-	return strings.Join(sorter, "\n")
-}
 
-func mockCPU(def []byte) func() {
-	lines := strings.Split(string(def), "\n")
-	anyfound := false
-	fakeID := make(fakecpuid)
-	for _, line := range lines {
-		line = strings.Trim(line, "\r\t ")
-		if !strings.HasPrefix(line, "CPUID") {
-			continue
-		}
-		// Only collect for first cpu
-		if strings.HasPrefix(line, "CPUID 00000000") {
-			if anyfound {
-				break
-			}
-		}
-		//if !strings.Contains(line, "-") {
-		//	continue
-		//}
-		items := strings.Split(line, ":")
-		if len(items) < 2 {
-			if len(line) == 51 || len(line) == 50 {
-				items = []string{line[0:14], line[15:]}
-			} else {
-				items = strings.Split(line, "\t")
-				if len(items) != 2 {
-					//fmt.Println("not found:", line, "len:", len(line))
-					continue
-				}
+	for _, name := range Profiles() {
+		p, ok := Profile(strings.ToUpper(name))
+		if !ok || p.Len() == 0 {
+			t.Errorf("profile %s not found", name)
+		}
+		for _, id := range p.IDs() {
+			if !p.ContainsAll(Implies(id)) {
+				t.Errorf("profile %s: %v is missing prerequisites %v", name, id, Implies(id).Difference(p))
 			}
 		}
-		items = items[0:2]
-		vals := strings.Trim(items[1], "\r\n ")
+	}
+	for _, name := range []string{"pentium", "neoverse-n2", "neoverse-v2", "graviton4"} {
+		if _, ok := Profile(name); ok {
+			t.Errorf("unknown profile %s found", name)
+		}
+	}
+}
 
-		var idV uint32
-		n, err := fmt.Sscanf(items[0], "CPUID %x", &idV)
-		if err != nil || n != 1 {
-			continue
+func TestMicroarchDumps(t *testing.T) {
+	// Dump name fragments with the expected generation and optionally codename.
+	// The first match is used.
+	tests := []struct {
+		name     string
+		gen      Generation
+		codename string
+	}{
+		{name: "_P4", gen: NetBurst},
+		{name: "_P3_", gen: P6},
+		{name: "_PM_", gen: PentiumM},
+		{name: "Conroe", gen: Core2},
+		{name: "Penryn", gen: Penryn},
+		{name: "_Nehalem", gen: Nehalem},
+		{name: "Gulftown", gen: Westmere},
+		{name: "SandyBridge", gen: SandyBridge},
+		{name: "IvyBridge", gen: IvyBridge},
+		{name: "Haswell", gen: Haswell},
+		{name: "Broadwell", gen: Broadwell},
+		{name: "CascadeLake", gen: Skylake, codename: "Cascade Lake"},
+		{name: "Skylake", gen: Skylake},
+		{name: "CoffeeLake", gen: Skylake, codename: "Coffee Lake"},
+		{name: "ICX", gen: SunnyCove},
+		{name: "RocketLake", gen: SunnyCove},
+		{name: "TigerLake", gen: WillowCove},
+		{name: "AlderLakeN", gen: Gracemont},
+		{name: "AlderLake", gen: GoldenCove, codename: "Alder Lake"},
+		{name: "SapphireRapids", gen: GoldenCove, codename: "Sapphire Rapids"},
+		{name: "RaptorLake", gen: RaptorCove, codename: "Raptor Lake"},
+		{name: "EmeraldRapids", gen: RaptorCove},
+		{name: "MeteorLake", gen: RedwoodCove},
+		{name: "ArrowLake", gen: LionCove},
+		{name: "LunarLake", gen: LionCove},
+		{name: "Silvermont", gen: Silvermont},
+		{name: "GoldmontPlus", gen: GoldmontPlus},
+		{name: "Goldmont", gen: Goldmont},
+		{name: "JasperLake", gen: Tremont},
+		{name: "_K5_", gen: K5},
+		{name: "_K6_", gen: K6},
+		{name: "_K7_", gen: K7},
+		{name: "_K8_", gen: K8},
+		{name: "_K10_", gen: K10},
+		{name: "Bobcat", gen: Bobcat},
+		{name: "Kabini", gen: Jaguar},
+		{name: "Beema", gen: Puma},
+		{name: "Bulldozer", gen: Bulldozer},
+		{name: "Vishera", gen: Piledriver},
+		{name: "Kaveri", gen: Steamroller},
+		{name: "Carrizo", gen: Excavator},
+		{name: "_ZenP", gen: ZenPlus},
+		{name: "_K17_Zen", gen: Zen},
+		{name: "Rome", gen: Zen2},
+		{name: "Matisse", gen: Zen2},
+		{name: "Milan", gen: Zen3, codename: "Milan"},
+		{name: "Vermeer", gen: Zen3},
+		{name: "Rembrandt", gen: Zen3},
+		{name: "Genoa", gen: Zen4, codename: "Genoa"},
+		{name: "Raphael", gen: Zen4},
+		{name: "Phoenix", gen: Zen4},
+		{name: "TurinD", gen: Zen5, codename: "Turin Dense"},
+		{name: "Turin", gen: Zen5, codename: "Turin"},
+		{name: "GraniteRidge", gen: Zen5},
+		{name: "Hygon", gen: Zen},
+	}
+	matched := make([]bool, len(tests))
+	forEachDump(t, func(name string, _ cpuidtest.Leaves, c CPUInfo) {
+		m := c.Microarch()
+		if m.Vendor != c.VendorID {
+			t.Errorf("%s: vendor %v, want %v", name, m.Vendor, c.VendorID)
 		}
-		existing, ok := fakeID[idV]
-		if !ok {
-			existing = make([][]uint32, 0)
+		// All Intel, AMD and Hygon CPUs after the 486 should be identified.
+		switch c.VendorID {
+		case Intel, AMD, Hygon:
+			if c.Family > 4 && m.Codename == "" {
+				t.Errorf("%s: family %#x model %#x not identified", name, c.Family, c.Model)
+			}
 		}
-
-		values := make([]uint32, 4)
-		n, err = fmt.Sscanf(vals, "%x-%x-%x-%x", &values[0], &values[1], &values[2], &values[3])
-		if n != 4 || err != nil {
-			n, err = fmt.Sscanf(vals, "%x %x %x %x", &values[0], &values[1], &values[2], &values[3])
-			if n != 4 || err != nil {
-				//fmt.Println("scanned", vals, "got", n, "Err:", err)
+		for i, test := range tests {
+			if !strings.Contains(name, test.name) {
 				continue
 			}
+			matched[i] = true
+			if m.Generation != test.gen {
+				t.Errorf("%s: got generation %v, want %v", name, m.Generation, test.gen)
+			}
+			if test.codename != "" && m.Codename != test.codename {
+				t.Errorf("%s: got codename %q, want %q", name, m.Codename, test.codename)
+			}
+			break
+		}
+	})
+	for i, test := range tests {
+		if !matched[i] {
+			t.Errorf("no dump matched %q", test.name)
 		}
-
-		existing = append(existing, values)
-		fakeID[idV] = existing
-		anyfound = true
 	}
+}
 
-	restorer := func(f idfuncs) func() {
-		return func() {
-			cpuid = f.cpuid
-			cpuidex = f.cpuidex
-			xgetbv = f.xgetbv
+func TestCachesDumps(t *testing.T) {
+	tests := map[string][]CacheInfo{
+		"AuthenticAMD0100F42_K10_Deneb_CPUID.txt": {
+			{Level: 1, Type: CacheTypeData, Size: 64 << 10, Ways: 2, Sets: 512, LineSize: 64, Partitions: 1, SharedBy: 1},
+			{Level: 1, Type: CacheTypeInstruction, Size: 64 << 10, Ways: 2, Sets: 512, LineSize: 64, Partitions: 1, SharedBy: 1},
+			{Level: 2, Type: CacheTypeUnified, Size: 512 << 10, Ways: 16, Sets: 512, LineSize: 64, Partitions: 1, SharedBy: 1},
+			{Level: 3, Type: CacheTypeUnified, Size: 6 << 20, Ways: 48, Sets: 2048, LineSize: 64, Partitions: 1},
+		},
+		"AuthenticAMD0870F10_K17_Matisse_11_CPUID.txt": {
+			{Level: 1, Type: CacheTypeData, Size: 32 << 10, Ways: 8, Sets: 64, LineSize: 64, Partitions: 1, SharedBy: 2},
+			{Level: 1, Type: CacheTypeInstruction, Size: 32 << 10, Ways: 8, Sets: 64, LineSize: 64, Partitions: 1, SharedBy: 2},
+			{Level: 2, Type: CacheTypeUnified, Size: 512 << 10, Ways: 8, Sets: 1024, LineSize: 64, Partitions: 1, SharedBy: 2, Inclusive: true},
+			{Level: 3, Type: CacheTypeUnified, Size: 16 << 20, Ways: 16, Sets: 16384, LineSize: 64, Partitions: 1, SharedBy: 6, WriteBack: true},
+		},
+		// Leaf 2 descriptors
+		"GenuineIntel0000673_P3_Katmai_CPUID.txt": {
+			{Level: 1, Type: CacheTypeInstruction, Size: 16 << 10, Ways: 4, Sets: 128, LineSize: 32, Partitions: 1},
+			{Level: 1, Type: CacheTypeData, Size: 16 << 10, Ways: 4, Sets: 128, LineSize: 32, Partitions: 1},
+			{Level: 2, Type: CacheTypeUnified, Size: 512 << 10, Ways: 4, Sets: 4096, LineSize: 32, Partitions: 1},
+		},
+		// Centaur 0x80000006 with the L1 layout
+		"CentaurHauls0000673_C5B_Samuel2_CPUID.txt": {
+			{Level: 1, Type: CacheTypeData, Size: 64 << 10, Ways: 4, Sets: 512, LineSize: 32, Partitions: 1, SharedBy: 1},
+			{Level: 1, Type: CacheTypeInstruction, Size: 64 << 10, Ways: 4, Sets: 512, LineSize: 32, Partitions: 1, SharedBy: 1},
+			{Level: 2, Type: CacheTypeUnified, Size: 64 << 10, Ways: 4, Sets: 512, LineSize: 32, Partitions: 1, SharedBy: 1},
+		},
+		// Centaur 0x80000006 reporting 65KB
+		"CentaurHauls0000691_C5XL_Nehemiah_CPUID.txt": {
+			{Level: 1, Type: CacheTypeData, Size: 64 << 10, Ways: 4, Sets: 512, LineSize: 32, Partitions: 1, SharedBy: 1},
+			{Level: 1, Type: CacheTypeInstruction, Size: 64 << 10, Ways: 4, Sets: 512, LineSize: 32, Partitions: 1, SharedBy: 1},
+			{Level: 2, Type: CacheTypeUnified, Size: 64 << 10, LineSize: 32, Partitions: 1, SharedBy: 1},
+		},
+		"CentaurHauls00006A9_C5J_Esther_CPUID.txt": {
+			{Level: 1, Type: CacheTypeData, Size: 64 << 10, Ways: 4, Sets: 256, LineSize: 64, Partitions: 1, SharedBy: 1},
+			{Level: 1, Type: CacheTypeInstruction, Size: 64 << 10, Ways: 4, Sets: 256, LineSize: 64, Partitions: 1, SharedBy: 1},
+			{Level: 2, Type: CacheTypeUnified, Size: 128 << 10, Ways: 32, Sets: 64, LineSize: 64, Partitions: 1, SharedBy: 1},
+		},
+		"GenuineIntel0050654_SkylakeX_CPUID.txt": {
+			{Level: 1, Type: CacheTypeData, Size: 32 << 10, Ways: 8, Sets: 64, LineSize: 64, Partitions: 1, SharedBy: 2},
+			{Level: 1, Type: CacheTypeInstruction, Size: 32 << 10, Ways: 8, Sets: 64, LineSize: 64, Partitions: 1, SharedBy: 2},
+			{Level: 2, Type: CacheTypeUnified, Size: 1 << 20, Ways: 16, Sets: 1024, LineSize: 64, Partitions: 1, SharedBy: 2},
+			{Level: 3, Type: CacheTypeUnified, Size: 14080 << 10, Ways: 11, Sets: 20480, LineSize: 64, Partitions: 1, SharedBy: 32, ComplexIndexing: true},
+		},
+	}
+	forEachDump(t, func(name string, leaves cpuidtest.Leaves, c CPUInfo) {
+		for _, ci := range c.Caches {
+			if ci.Level < 1 || ci.Level > 4 || ci.Size <= 0 {
+				t.Errorf("%s: invalid cache %+v", name, ci)
+			}
+			if ci.Sets > 0 && ci.Size != ci.Ways*ci.Sets*ci.LineSize*ci.Partitions {
+				t.Errorf("%s: size mismatch %+v", name, ci)
+			}
+			if ci.Level == 2 && ci.Size != c.Cache.L2 {
+				t.Errorf("%s: L2 is %d, cache %+v", name, c.Cache.L2, ci)
+			}
 		}
-	}(idfuncs{cpuid: cpuid, cpuidex: cpuidex, xgetbv: xgetbv})
-
-	cpuid = func(op uint32) (eax, ebx, ecx, edx uint32) {
-		if op == 0x80000000 || op == 0 || op == 0x4000000c || op == 0x40000000 {
-			var ok bool
-			_, ok = fakeID[op]
-			if !ok {
-				return 0, 0, 0, 0
+		// Caches should be found for CPUs that report them.
+		switch c.VendorID {
+		case Intel, AMD, Hygon, VIA, Zhaoxin:
+			if c.Cache.L1D <= 0 && (c.Family > 6 || c.Family == 6 && c.Model > 5) {
+				t.Errorf("%s: no L1 data cache", name)
 			}
 		}
-		first, ok := fakeID[op]
-		if !ok {
-			if op > maxFunctionID() {
-				panic(fmt.Sprintf("Base not found: %v, request:%#v\n", fakeID, op))
-			} else {
-				// we have some entries missing
-				return 0, 0, 0, 0
+		// Without TOPEXT the caches are read from leaf 0x80000005 and 0x80000006.
+		// Associativity not reported there must be read from leaf 0x8000001D.
+		if (c.VendorID == AMD || c.VendorID == Hygon) && c.Has(TOPEXT) {
+			legacy := make(cpuidtest.Leaves, len(leaves))
+			for k, v := range leaves {
+				legacy[k] = v
+			}
+			e := legacy[cpuidtest.Leaf{Op: 0x80000001}]
+			e[2] &^= 1 << 22
+			legacy[cpuidtest.Leaf{Op: 0x80000001}] = e
+			lc := cpuidtest.DetectDump(legacy)
+			for _, ci := range lc.Caches {
+				if ci.Level < 2 {
+					continue
+				}
+				for _, d := range c.Caches {
+					if d.Level == ci.Level && d.Type == ci.Type && d.Size == ci.Size && (d.Ways != ci.Ways || d.Sets != ci.Sets) {
+						t.Errorf("%s: without TOPEXT got %+v, want ways and sets of %+v", name, ci, d)
+					}
+				}
 			}
 		}
-		theid := first[0]
-		return theid[0], theid[1], theid[2], theid[3]
+		want, ok := tests[name]
+		if !ok {
+			return
+		}
+		delete(tests, name)
+		if !reflect.DeepEqual(c.Caches, want) {
+			t.Errorf("%s: got caches\n%+v\nwant\n%+v", name, c.Caches, want)
+		}
+	})
+	for name := range tests {
+		t.Errorf("dump %s not found", name)
+	}
+}
+
+func TestTLBsDumps(t *testing.T) {
+	// TLBs that must be reported for each dump.
+	tests := map[string][]TLBInfo{
+		// Leaf 2 descriptors
+		"GenuineIntel00006F6_Conroe_CPUID.txt": {
+			{Level: 1, Type: TLBTypeInstruction, PageSizes: Page4K, Entries: 128, Ways: 4},
+			{Level: 1, Type: TLBTypeData, PageSizes: Page4K, Entries: 256, Ways: 4},
+		},
+		"GenuineIntel0050654_SkylakeX_CPUID.txt": {
+			{Level: 1, Type: TLBTypeInstruction, PageSizes: Page2M | Page4M, Entries: 8, Ways: 8, FullyAssociative: true},
+			{Level: 2, Type: TLBTypeUnified, PageSizes: Page4K | Page2M, Entries: 1536, Ways: 6},
+			{Level: 2, Type: TLBTypeUnified, PageSizes: Page1G, Entries: 16, Ways: 4},
+		},
+		// Leaf 0x18
+		"GenuineIntel0090672_AlderLake_02_CPUID.txt": {
+			{Level: 1, Type: TLBTypeStore, PageSizes: Page4K | Page2M | Page4M | Page1G, Entries: 16, Ways: 16, FullyAssociative: true, SharedBy: 2},
+			{Level: 1, Type: TLBTypeLoad, PageSizes: Page4K, Entries: 64, Ways: 4, SharedBy: 2},
+			{Level: 2, Type: TLBTypeUnified, PageSizes: Page4K | Page2M | Page4M, Entries: 1024, Ways: 8, SharedBy: 2},
+		},
+		// AMD 0x80000005, 0x80000006 and 0x80000019
+		"AuthenticAMD0870F10_K17_Matisse_11_CPUID.txt": {
+			{Level: 1, Type: TLBTypeData, PageSizes: Page4K, Entries: 64, Ways: 64, FullyAssociative: true, SharedBy: 2},
+			{Level: 2, Type: TLBTypeData, PageSizes: Page4K, Entries: 2048, Ways: 8, SharedBy: 2},
+			{Level: 2, Type: TLBTypeData, PageSizes: Page2M | Page4M, Entries: 2048, Ways: 4, SharedBy: 2},
+			{Level: 1, Type: TLBTypeInstruction, PageSizes: Page1G, Entries: 64, Ways: 64, FullyAssociative: true, SharedBy: 2},
+		},
 	}
-	cpuidex = func(op, op2 uint32) (eax, ebx, ecx, edx uint32) {
-		if op == 0x80000000 {
-			var ok bool
-			_, ok = fakeID[op]
-			if !ok {
-				return 0, 0, 0, 0
+	forEachDump(t, func(name string, _ cpuidtest.Leaves, c CPUInfo) {
+		for _, tlb := range c.TLBs {
+			if tlb.Level < 1 || tlb.Entries <= 0 || tlb.PageSizes == 0 || tlb.Type == TLBTypeUnknown {
+				t.Errorf("%s: invalid TLB %+v", name, tlb)
+			}
+			if tlb.FullyAssociative && tlb.Ways != tlb.Entries {
+				t.Errorf("%s: fully associative TLB %+v", name, tlb)
 			}
 		}
-		first, ok := fakeID[op]
+		want, ok := tests[name]
 		if !ok {
-			if op > maxExtendedFunction() {
-				panic(fmt.Sprintf("Extended not found Info: %v, request:%#v, %#v\n", fakeID, op, op2))
-			} else {
-				// we have some entries missing
-				return 0, 0, 0, 0
-			}
+			return
 		}
-		if int(op2) >= len(first) {
-			//fmt.Printf("Extended not found Info: %v, request:%#v, %#v\n", fakeID, op, op2)
-			return 0, 0, 0, 0
+		delete(tests, name)
+	wantLoop:
+		for _, w := range want {
+			for _, tlb := range c.TLBs {
+				if tlb == w {
+					continue wantLoop
+				}
+			}
+			t.Errorf("%s: TLB %+v not found in %+v", name, w, c.TLBs)
 		}
-		theid := first[op2]
-		return theid[0], theid[1], theid[2], theid[3]
+	})
+	for name := range tests {
+		t.Errorf("dump %s not found", name)
+	}
+}
+
+func TestTopologyDumps(t *testing.T) {
+	tests := map[string][]TopologyLevel{
+		// Leaf 0x80000026
+		"AuthenticAMD0A10F11_K19_Genoa_01_CPUID.txt": {
+			{Type: TopologyCore, Shift: 1, LogicalCPUs: 2},
+			{Type: TopologyComplex, Shift: 4, LogicalCPUs: 16},
+			{Type: TopologyDie, Shift: 4, LogicalCPUs: 16},
+			{Type: TopologyPackage, Shift: 8, LogicalCPUs: 192},
+		},
+		// Leaf 0xB with complexes from the L3 cache
+		"AuthenticAMD0870F10_K17_Matisse_11_CPUID.txt": {
+			{Type: TopologyCore, Shift: 1, LogicalCPUs: 2},
+			{Type: TopologyComplex, Shift: 3, LogicalCPUs: 6},
+			{Type: TopologyPackage, Shift: 7, LogicalCPUs: 12},
+		},
+		// Zen 1 nodes from leaf 0x8000001E
+		"AuthenticAMD0800F12_K17_Zen_CPUID.txt": {
+			{Type: TopologyCore, Shift: 1, LogicalCPUs: 2},
+			{Type: TopologyComplex, Shift: 3, LogicalCPUs: 8},
+			{Type: TopologyDie, Shift: 4, LogicalCPUs: 16},
+			{Type: TopologyPackage, Shift: 6, LogicalCPUs: 64},
+		},
+		// Compute units from leaf 0x8000001E
+		"AuthenticAMD0600F20_K15_Vishera_CPUID.txt": {
+			{Type: TopologyCore, Shift: 0, LogicalCPUs: 1},
+			{Type: TopologyModule, Shift: 1, LogicalCPUs: 2},
+			{Type: TopologyPackage, Shift: 4, LogicalCPUs: 8},
+		},
+		// Leaf 0x1F
+		"GenuineIntel00806F8_SapphireRapids_05_CPUID.txt": {
+			{Type: TopologyCore, Shift: 1, LogicalCPUs: 2},
+			{Type: TopologyPackage, Shift: 7, LogicalCPUs: 40},
+		},
+		// Leaf 1 and leaf 4
+		"GenuineIntel00006F6_Conroe_CPUID.txt": {
+			{Type: TopologyCore, Shift: 0, LogicalCPUs: 1},
+			{Type: TopologyPackage, Shift: 1, LogicalCPUs: 2},
+		},
+		// Leaf 0x80000008
+		"AuthenticAMD0100F81_K10_Lisbon_CPUID.txt": {
+			{Type: TopologyCore, Shift: 0, LogicalCPUs: 1},
+			{Type: TopologyPackage, Shift: 3, LogicalCPUs: 6},
+		},
 	}
-	xgetbv = func(index uint32) (eax, edx uint32) {
-		first, ok := fakeID[1]
+	forEachDump(t, func(name string, _ cpuidtest.Leaves, c CPUInfo) {
+		topo := c.Topology
+		if c.VendorID == Intel || c.VendorID == AMD {
+			if _, ok := topo.Level(TopologyPackage); !ok {
+				t.Errorf("%s: no package level in %+v", name, topo.Levels)
+			}
+		}
+		for i, l := range topo.Levels {
+			if l.LogicalCPUs <= 0 || l.LogicalCPUs > 1<<l.Shift {
+				t.Errorf("%s: invalid level %+v", name, l)
+			}
+			if i > 0 {
+				if prev := topo.Levels[i-1]; prev.Type >= l.Type || prev.Shift > l.Shift || prev.LogicalCPUs > l.LogicalCPUs {
+					t.Errorf("%s: level %+v after %+v", name, l, prev)
+				}
+			}
+		}
+		want, ok := tests[name]
 		if !ok {
-			panic(fmt.Sprintf("XGETBV not supported %v", fakeID))
+			return
 		}
-		second := first[0]
-		// ECX bit 26 must be set
-		if (second[2] & 1 << 26) == 0 {
-			panic(fmt.Sprintf("XGETBV not supported %v", fakeID))
+		delete(tests, name)
+		if !reflect.DeepEqual(topo.Levels, want) {
+			t.Errorf("%s: got levels %+v, want %+v", name, topo.Levels, want)
 		}
-		// We don't have any data to return, unfortunately
-		return math.MaxUint32, math.MaxUint32
+	})
+	for name := range tests {
+		t.Errorf("dump %s not found", name)
 	}
-	return restorer
 }
 
-func TestMocks(t *testing.T) {
+// forEachDump calls fn with the name, leaves and detected information of each dump in testdata/cpuid_data.zip.
+// The test is skipped if the dumps are not available.
+func forEachDump(t *testing.T, fn func(name string, leaves cpuidtest.Leaves, c CPUInfo)) {
+	t.Helper()
 	zr, err := zip.OpenReader("testdata/cpuid_data.zip")
 	if err != nil {
 		t.Skip("No testdata:", err)
 	}
 	defer zr.Close()
 	for _, f := range zr.File {
-		t.Run(filepath.Base(f.Name), func(t *testing.T) {
-			rc, err := f.Open()
-			if err != nil {
-				t.Fatal(err)
-			}
-			content, err := ioutil.ReadAll(rc)
-			if err != nil {
-				t.Fatal(err)
-			}
-			rc.Close()
-			t.Log("Opening", f.FileInfo().Name())
-			restore := mockCPU(content)
-			Detect()
-			t.Log("Name:", CPU.BrandName)
-			n := maxFunctionID()
-			t.Logf("Max Function:0x%x", n)
-			n = maxExtendedFunction()
-			t.Logf("Max Extended Function:0x%x", n)
-			t.Log("VendorString:", CPU.VendorString)
-			t.Log("VendorID:", CPU.VendorID)
-			t.Log("PhysicalCores:", CPU.PhysicalCores)
-			t.Log("ThreadsPerCore:", CPU.ThreadsPerCore)
-			t.Log("LogicalCores:", CPU.LogicalCores)
-			t.Log("Family", CPU.Family, "Model:", CPU.Model, "Stepping:", CPU.Stepping)
-			t.Log("Features:", strings.Join(CPU.FeatureSet(), ","))
-			t.Log("Microarchitecture level:", CPU.X64Level())
-			t.Log("Cacheline bytes:", CPU.CacheLine)
-			t.Log("L1 Instruction Cache:", CPU.Cache.L1I, "bytes")
-			t.Log("L1 Data Cache:", CPU.Cache.L1D, "bytes")
-			t.Log("L2 Cache:", CPU.Cache.L2, "bytes")
-			t.Log("L3 Cache:", CPU.Cache.L3, "bytes")
-			t.Log("Hz:", CPU.Hz, "Hz")
-			t.Log("Boost:", CPU.BoostFreq, "Hz")
-			if CPU.AVX10Level > 0 {
-				t.Log("AVX10 level:", CPU.AVX10Level)
-			}
-			if CPU.LogicalCores > 0 && CPU.PhysicalCores > 0 {
-				if CPU.LogicalCores != CPU.PhysicalCores*CPU.ThreadsPerCore {
-					t.Fatalf("Core count mismatch, LogicalCores (%d) != PhysicalCores (%d) * CPU.ThreadsPerCore (%d)",
-						CPU.LogicalCores, CPU.PhysicalCores, CPU.ThreadsPerCore)
-				}
-			}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaves, err := cpuidtest.ParseDump(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(f.Name, err)
+		}
+		fn(filepath.Base(f.Name), leaves, cpuidtest.DetectDump(leaves))
+	}
+}
 
-			if CPU.ThreadsPerCore > 1 && !CPU.Supports(HTT) {
-				t.Fatalf("Hyperthreading not detected")
-			}
-			if CPU.ThreadsPerCore == 1 && CPU.Supports(HTT) {
-				t.Fatalf("Hyperthreading detected, but only 1 Thread per core")
-			}
-			restore()
-		})
+// describe returns the decoded information of c.
+func describe(c CPUInfo) string {
+	return fmt.Sprintf("%q %v %v %d %d %d %d %d %d %d %d %d %d %d %+v %+v %+v %+v %+v %+v %d %+v %v",
+		c.BrandName, c.VendorID, c.FeatureSet(), c.PhysicalCores, c.ThreadsPerCore, c.LogicalCores,
+		c.Family, c.Model, c.Stepping, c.Variant, c.Revision, c.CacheLine, c.Hz, c.BoostFreq, c.Cache, c.Caches, c.TLBs, c.Topology, c.SGX,
+		c.AMDMemEncryption, c.AVX10Level, c.PMU, c.Partial)
+}
+
+func TestLeafFromDump(t *testing.T) {
+	leaves, err := cpuidtest.ParseDump(strings.NewReader(`CPUID 00000000: 00000007-756E6547-6C65746E-49656E69
+CPUID 00000001: 000906EA-00100800-7FFAFBBF-BFEBFBFF
+CPUID 00000007: 00000000-029C67AF-40000000-BC000400 [SL 00]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := cpuidtest.DetectDump(leaves)
+	if eax, ebx, ecx, edx := c.Leaf(7, 0); eax != 0 || ebx != 0x029C67AF || ecx != 0x40000000 || edx != 0xBC000400 {
+		t.Errorf("leaf 7: got %08x %08x %08x %08x", eax, ebx, ecx, edx)
+	}
+	if eax, _, _, _ := c.Leaf(1, 0); eax != 0x906EA {
+		t.Errorf("leaf 1: got eax %08x", eax)
 	}
+	if eax, ebx, ecx, edx := c.Leaf(7, 1); eax|ebx|ecx|edx != 0 {
+		t.Error("leaf 7, subleaf 1 should be empty")
+	}
+}
 
-	Detect()
+func TestFingerprint(t *testing.T) {
+	leaves, err := cpuidtest.ParseDump(strings.NewReader(`CPUID 00000000: 00000007-756E6547-6C65746E-49656E69
+CPUID 00000001: 000906EA-00100800-7FFAFBBF-BFEBFBFF
+CPUID 00000007: 00000000-029C67AF-40000000-BC000400 [SL 00]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := cpuidtest.DetectDump(leaves)
+	// This value must not change between releases.
+	const want uint64 = 0x2f02b981c3d4f799
+	if got := c.Fingerprint(); got != want {
+		t.Errorf("fingerprint changed: got %#x, want %#x", got, want)
+	}
+	c.Disable(AVX2)
+	if c.Fingerprint() == want {
+		t.Error("fingerprint did not change when disabling a feature")
+	}
+}
 
+func TestDetectFrom(t *testing.T) {
+	if _, err := DetectFrom(nil); err == nil {
+		t.Fatal("expected error on nil source")
+	}
+	before := strings.Join(CPU.FeatureSet(), ",")
+	leaves, err := cpuidtest.ParseDump(strings.NewReader(`CPUID 00000000: 00000001-756E6547-6C65746E-49656E69
+CPUID 00000001: 00000633-00000000-00000000-0080F9FF`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cpuidtest.DetectDump(leaves)
+	if got.VendorID != Intel || got.Family != 6 || got.Model != 3 || got.Stepping != 3 {
+		t.Errorf("unexpected decode: %v family %d model %d stepping %d", got.VendorID, got.Family, got.Model, got.Stepping)
+	}
+	if !got.Supports(MMX, CMOV) || got.Has(SSE) {
+		t.Error("unexpected features:", got.FeatureSet())
+	}
+	if strings.Join(CPU.FeatureSet(), ",") != before {
+		t.Fatal("DetectFrom modified CPU")
+	}
 }
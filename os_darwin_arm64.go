@@ -81,6 +81,9 @@ func tryToFillCPUInfoFomSysctl(c *CPUInfo) {
 	if len(c.BrandName) != 0 {
 		c.VendorString = strings.Fields(c.BrandName)[0]
 	}
+	if c.VendorString == "Apple" {
+		c.VendorID = Apple
+	}
 
 	c.PhysicalCores = sysctlGetInt(runtime.NumCPU(), "hw.physicalcpu")
 	c.ThreadsPerCore = sysctlGetInt(1, "machdep.cpu.thread_count", "kern.num_threads") /
@@ -0,0 +1,120 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import "strings"
+
+// Feature sets of well-known microarchitectures.
+// Each set only contains features detected by this package,
+// and excludes features that are commonly disabled, like TSX and SGX.
+var (
+	profileNehalem = CombineFeatures(CMOV, CMPXCHG8, X87, FXSR, MMX, SSE, SSE2, CX16, LAHF, POPCNT, SSE3, SSE4, SSE42, SSSE3)
+
+	profileSandyBridge   = extendProfile(profileNehalem, AESNI, CLMUL, XSAVE, OSXSAVE, AVX)
+	profileHaswell       = extendProfile(profileSandyBridge, F16C, RDRAND, AVX2, BMI1, BMI2, FMA3, LZCNT, MOVBE)
+	profileSkylake       = extendProfile(profileHaswell, ADX, RDSEED, XSAVEOPT, XSAVEC, XSAVES)
+	profileSkylakeAVX512 = extendProfile(profileSkylake, AVX512F, AVX512CD, AVX512BW, AVX512DQ, AVX512VL)
+	profileIceLakeServer = extendProfile(profileSkylakeAVX512, AVX512VNNI, AVX512IFMA, AVX512VBMI, AVX512VBMI2,
+		AVX512BITALG, AVX512VPOPCNTDQ, GFNI, VAES, VPCLMULQDQ, SHA)
+	profileSapphireRapids = extendProfile(profileIceLakeServer, AVX512BF16, AVX512FP16, AVXVNNI, AMXTILE, AMXINT8, AMXBF16,
+		SERIALIZE, MOVDIRI, MOVDIR64B, ENQCMD, CLDEMOTE, WAITPKG)
+	profileGraniteRapids = extendProfile(profileSapphireRapids, AMXFP16, PREFETCHI)
+
+	profileZen1 = extendProfile(profileHaswell, ADX, RDSEED, SHA, XSAVEOPT, XSAVEC, XSAVES, SSE4A, CLZERO)
+	profileZen2 = extendProfile(profileZen1, WBNOINVD)
+	profileZen3 = extendProfile(profileZen2, VAES, VPCLMULQDQ)
+	profileZen4 = extendProfile(profileZen3, AVX512F, AVX512CD, AVX512BW, AVX512DQ, AVX512VL, AVX512IFMA, AVX512VBMI,
+		AVX512VBMI2, AVX512VNNI, AVX512BITALG, AVX512VPOPCNTDQ, AVX512BF16, GFNI)
+	profileZen5 = extendProfile(profileZen4, AVXVNNI, AVX512VP2INTERSECT, MOVDIRI, MOVDIR64B)
+
+	profileNeoverseN1 = CombineFeatures(FP, ASIMD, AESARM, PMULL, SHA1, SHA2, CRC32, ATOMICS, FPHP, ASIMDHP, ASIMDRDM,
+		LRCPC, DCPOP, ASIMDDP)
+	profileNeoverseV1 = extendProfile(profileNeoverseN1, JSCVT, FCMA, SHA3, SHA512, FHM, SVE)
+)
+
+// profiles contains the named profiles returned by Profiles.
+// Names follow the -march names of GCC.
+// Neoverse N2 and V2 (Graviton 4) are not included,
+// since the features they add, like SVE2 and I8MM, are not detected.
+var profiles = []struct {
+	name     string
+	aliases  []string
+	features Features
+}{
+	{name: "x86-64", aliases: []string{"x86-64-v1"}, features: level1Features},
+	{name: "x86-64-v2", features: level2Features},
+	{name: "x86-64-v3", features: extendProfile(level3Features, XSAVE)},
+	{name: "x86-64-v4", features: extendProfile(level4Features, XSAVE)},
+	{name: "nehalem", features: profileNehalem},
+	{name: "sandybridge", features: profileSandyBridge},
+	{name: "haswell", features: profileHaswell},
+	{name: "skylake", features: profileSkylake},
+	{name: "skylake-avx512", features: profileSkylakeAVX512},
+	{name: "icelake-server", features: profileIceLakeServer},
+	{name: "sapphirerapids", features: profileSapphireRapids},
+	{name: "graniterapids", features: profileGraniteRapids},
+	{name: "znver1", aliases: []string{"zen", "zen1"}, features: profileZen1},
+	{name: "znver2", aliases: []string{"zen2"}, features: profileZen2},
+	{name: "znver3", aliases: []string{"zen3"}, features: profileZen3},
+	{name: "znver4", aliases: []string{"zen4"}, features: profileZen4},
+	{name: "znver5", aliases: []string{"zen5"}, features: profileZen5},
+	{name: "neoverse-n1", features: profileNeoverseN1},
+	{name: "neoverse-v1", features: profileNeoverseV1},
+	{name: "graviton2", features: profileNeoverseN1},
+	{name: "graviton3", features: profileNeoverseV1},
+}
+
+// extendProfile returns the features of base with ids added.
+func extendProfile(base Features, ids ...FeatureID) Features {
+	v := *base
+	v.or(*CombineFeatures(ids...))
+	return &v
+}
+
+// normalizeProfileName returns name in lower case without spaces, dashes and underscores.
+func normalizeProfileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// Profile returns the features of a named profile.
+// Names are not case sensitive and dashes, underscores and spaces are ignored,
+// so "Sandy Bridge" and "sandybridge" are the same.
+// Zen generations can also be given as "zen1" to "zen5".
+// The x86-64 microarchitecture levels are available as "x86-64" and "x86-64-v2" to "x86-64-v4".
+// Note that these do not contain SYSCALL, which X64Level also requires,
+// but contain XSAVE, which is required for AVX.
+func Profile(name string) (FeatureSet, bool) {
+	name = normalizeProfileName(name)
+	for _, p := range profiles {
+		if normalizeProfileName(p.name) == name {
+			return FeatureSet{s: *p.features}, true
+		}
+		for _, alias := range p.aliases {
+			if normalizeProfileName(alias) == name {
+				return FeatureSet{s: *p.features}, true
+			}
+		}
+	}
+	return FeatureSet{}, false
+}
+
+// Profiles returns the names of all profiles.
+func Profiles() []string {
+	names := make([]string, len(profiles))
+	for i, p := range profiles {
+		names[i] = p.name
+	}
+	return names
+}
+
+// Satisfies returns whether the CPU has all features of profile.
+// Use profile.Difference(c.Features()) to get the missing features.
+func (c CPUInfo) Satisfies(profile FeatureSet) bool {
+	return c.Features().ContainsAll(profile)
+}
@@ -0,0 +1,304 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Requirement is a boolean expression of features,
+// for example "AVX2 && BMI2 && (ADX || AVX512IFMA)".
+// The zero value has no requirements and is always satisfied.
+type Requirement struct {
+	src string
+	// terms is the expression in disjunctive normal form.
+	// The requirement is satisfied if all features of any term are present.
+	terms []flagSet
+}
+
+// maxRequirementTerms limits the size of a compiled requirement.
+const maxRequirementTerms = 256
+
+// ParseRequirement parses a requirement expression.
+// Features are combined with "&&" and "||" and can be grouped with parentheses.
+// "&&" binds tighter than "||". Feature names are not case sensitive.
+func ParseRequirement(s string) (Requirement, error) {
+	p := reqParser{s: s}
+	n, err := p.parseOr()
+	if err == nil && p.peek() != "" {
+		err = p.errorf("unexpected %q", p.peek())
+	}
+	if err != nil {
+		return Requirement{}, err
+	}
+	terms, err := n.compile()
+	if err != nil {
+		return Requirement{}, err
+	}
+	return Requirement{src: n.String(), terms: terms}, nil
+}
+
+// MustParseRequirement is like ParseRequirement but panics if the expression cannot be parsed.
+func MustParseRequirement(s string) Requirement {
+	r, err := ParseRequirement(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Eval returns whether c satisfies the requirement.
+// If c is nil, the current snapshot is used.
+func (r Requirement) Eval(c *CPUInfo) bool {
+	if len(r.terms) == 0 {
+		return true
+	}
+	if c == nil {
+		c = current.Load()
+	}
+	for i := range r.terms {
+		if c.featureSet.hasSetP(&r.terms[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Missing returns the smallest set of features that c lacks to satisfy the requirement.
+// If several sets have the same size, the first in the expression is returned.
+// The set is empty if the requirement is satisfied.
+// If c is nil, the current snapshot is used.
+func (r Requirement) Missing(c *CPUInfo) FeatureSet {
+	if c == nil {
+		c = current.Load()
+	}
+	var best flagSet
+	bestN := -1
+	for _, t := range r.terms {
+		for i := range t {
+			t[i] &^= c.featureSet[i]
+		}
+		if n := t.nEnabled(); bestN < 0 || n < bestN {
+			best, bestN = t, n
+			if n == 0 {
+				break
+			}
+		}
+	}
+	return FeatureSet{s: best}
+}
+
+// String returns the requirement expression with canonical feature names.
+func (r Requirement) String() string {
+	return r.src
+}
+
+// MarshalText returns the requirement expression.
+func (r Requirement) MarshalText() ([]byte, error) {
+	return []byte(r.src), nil
+}
+
+// UnmarshalText parses a requirement expression.
+func (r *Requirement) UnmarshalText(b []byte) error {
+	v, err := ParseRequirement(string(b))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// reqNode is a node in a parsed requirement.
+// Nodes without children are features.
+type reqNode struct {
+	id  FeatureID
+	and bool
+	sub []*reqNode
+}
+
+func (n *reqNode) String() string {
+	if len(n.sub) == 0 {
+		return n.id.String()
+	}
+	op := " || "
+	if n.and {
+		op = " && "
+	}
+	parts := make([]string, len(n.sub))
+	for i, s := range n.sub {
+		parts[i] = s.String()
+		if n.and && len(s.sub) > 0 && !s.and {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, op)
+}
+
+// compile returns n in disjunctive normal form.
+func (n *reqNode) compile() ([]flagSet, error) {
+	if len(n.sub) == 0 {
+		return []flagSet{flagSetWith(n.id)}, nil
+	}
+	var res []flagSet
+	for i, s := range n.sub {
+		terms, err := s.compile()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case i == 0:
+			res = terms
+		case n.and:
+			// Check the size before multiplying the terms out.
+			if len(res)*len(terms) > maxRequirementTerms {
+				return nil, errors.New("cpuid: requirement too complex")
+			}
+			prod := make([]flagSet, 0, len(res)*len(terms))
+			for _, a := range res {
+				for _, b := range terms {
+					a := a
+					a.or(b)
+					prod = append(prod, a)
+				}
+			}
+			res = prod
+		default:
+			res = append(res, terms...)
+		}
+		res = reduceTerms(res)
+		if len(res) > maxRequirementTerms {
+			return nil, errors.New("cpuid: requirement too complex")
+		}
+	}
+	return res, nil
+}
+
+// reduceTerms removes terms that are supersets of other terms,
+// since they can never be the only satisfied term.
+func reduceTerms(terms []flagSet) []flagSet {
+	res := make([]flagSet, 0, len(terms))
+	for i := range terms {
+		redundant := false
+		for j := range terms {
+			if i == j || !terms[i].hasSetP(&terms[j]) {
+				continue
+			}
+			// Keep the first of equal terms.
+			if terms[i] != terms[j] || j < i {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			res = append(res, terms[i])
+		}
+	}
+	return res
+}
+
+// reqParser is a recursive descent parser for requirements.
+type reqParser struct {
+	s   string
+	pos int
+}
+
+func (p *reqParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("cpuid: requirement %q: %s at offset %d", p.s, fmt.Sprintf(format, args...), p.pos)
+}
+
+// peek returns the next token, or "" at the end.
+func (p *reqParser) peek() string {
+	rest := strings.TrimLeft(p.s[p.pos:], " \t\r\n")
+	p.pos = len(p.s) - len(rest)
+	switch {
+	case rest == "":
+		return ""
+	case strings.HasPrefix(rest, "&&"), strings.HasPrefix(rest, "||"):
+		return rest[:2]
+	case !isFeatureNameChar(rest[0]):
+		return rest[:1]
+	}
+	n := 1
+	for n < len(rest) && isFeatureNameChar(rest[n]) {
+		n++
+	}
+	return rest[:n]
+}
+
+func (p *reqParser) next() string {
+	t := p.peek()
+	p.pos += len(t)
+	return t
+}
+
+func (p *reqParser) parseOr() (*reqNode, error) {
+	return p.parseList(false, p.parseAnd)
+}
+
+func (p *reqParser) parseAnd() (*reqNode, error) {
+	return p.parseList(true, p.parseFeature)
+}
+
+// parseList parses operands separated by "&&" or "||".
+func (p *reqParser) parseList(and bool, operand func() (*reqNode, error)) (*reqNode, error) {
+	op := "||"
+	if and {
+		op = "&&"
+	}
+	n := &reqNode{and: and}
+	for {
+		s, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		// Flatten nested operands of the same kind.
+		if len(s.sub) > 0 && s.and == and {
+			n.sub = append(n.sub, s.sub...)
+		} else {
+			n.sub = append(n.sub, s)
+		}
+		if p.peek() != op {
+			break
+		}
+		p.next()
+	}
+	if len(n.sub) == 1 {
+		return n.sub[0], nil
+	}
+	return n, nil
+}
+
+func (p *reqParser) parseFeature() (*reqNode, error) {
+	t := p.peek()
+	start := p.pos
+	p.pos += len(t)
+	switch {
+	case t == "":
+		return nil, p.errorf("unexpected end")
+	case t == "(":
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, p.errorf("missing )")
+		}
+		p.next()
+		return n, nil
+	case !isFeatureNameChar(t[0]) || t == "&&" || t == "||":
+		p.pos = start
+		return nil, p.errorf("unexpected %q", t)
+	}
+	id := ParseFeature(t)
+	if id == UNKNOWN {
+		p.pos = start
+		return nil, p.errorf("unknown feature %q", t)
+	}
+	return &reqNode{id: id}, nil
+}
+
+func isFeatureNameChar(b byte) bool {
+	return b == '_' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import "strings"
+
+// TLBType is the type of a TLB.
+type TLBType uint8
+
+// TLB types, with the values used by CPUID leaf 0x18.
+const (
+	TLBTypeUnknown     TLBType = 0
+	TLBTypeData        TLBType = 1
+	TLBTypeInstruction TLBType = 2
+	TLBTypeUnified     TLBType = 3
+	TLBTypeLoad        TLBType = 4 // Data TLB used for loads only
+	TLBTypeStore       TLBType = 5 // Data TLB used for stores only
+)
+
+// String returns the name of the TLB type.
+func (t TLBType) String() string {
+	switch t {
+	case TLBTypeData:
+		return "Data"
+	case TLBTypeInstruction:
+		return "Instruction"
+	case TLBTypeUnified:
+		return "Unified"
+	case TLBTypeLoad:
+		return "Load"
+	case TLBTypeStore:
+		return "Store"
+	}
+	return "Unknown"
+}
+
+// PageSizes is a set of page sizes.
+type PageSizes uint8
+
+// Page sizes, with the bits used by CPUID leaf 0x18.
+const (
+	Page4K PageSizes = 1 << iota
+	Page2M
+	Page4M
+	Page1G
+)
+
+// Has returns whether all page sizes of p are in s.
+func (s PageSizes) Has(p PageSizes) bool {
+	return s&p == p
+}
+
+// String returns the page sizes separated by '|', like "4K|2M".
+func (s PageSizes) String() string {
+	var names []string
+	for i, name := range []string{"4K", "2M", "4M", "1G"} {
+		if s&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// TLBInfo describes a translation lookaside buffer.
+// Fields are 0 if unknown.
+// A TLB that covers several page sizes may be reported once per page size.
+type TLBInfo struct {
+	Level            int
+	Type             TLBType
+	PageSizes        PageSizes
+	Entries          int
+	Ways             int  // Associativity. Equal to Entries if fully associative.
+	FullyAssociative bool // Any entry can be stored anywhere in the TLB
+	SharedBy         int  // Maximum number of logical CPUs sharing the TLB
+}
+
+// tlbs will fill TLBs.
+func (c *CPUInfo) tlbs() {
+	switch c.VendorID {
+	case Intel:
+		if c.maxFunc >= 0x18 {
+			c.TLBs = c.tlbsLeaf18()
+		}
+		if len(c.TLBs) == 0 {
+			for _, d := range c.leaf2Descriptors() {
+				c.TLBs = append(c.TLBs, leaf2TLBs[d]...)
+			}
+		}
+	case AMD, Hygon:
+		c.TLBs = c.tlbsAMD()
+	}
+}
+
+// tlbsLeaf18 reads the TLBs from the deterministic address translation parameters of leaf 0x18.
+func (c *CPUInfo) tlbsLeaf18() []TLBInfo {
+	var tlbs []TLBInfo
+	maxSub, _, _, _ := c.src.CPUIDEX(0x18, 0)
+	// Limit the number of subleafs in case of bogus values.
+	if maxSub > 64 {
+		maxSub = 64
+	}
+	for i := uint32(0); i <= maxSub; i++ {
+		_, ebx, ecx, edx := c.src.CPUIDEX(0x18, i)
+		typ := TLBType(edx & 0x1f)
+		if typ == TLBTypeUnknown {
+			continue
+		}
+		t := TLBInfo{
+			Level:            int(edx>>5) & 7,
+			Type:             typ,
+			PageSizes:        PageSizes(ebx & 0xf),
+			Ways:             int(ebx >> 16),
+			FullyAssociative: edx&(1<<8) != 0,
+			SharedBy:         int(edx>>14)&0xfff + 1,
+		}
+		t.Entries = t.Ways * int(ecx)
+		tlbs = append(tlbs, t)
+	}
+	return tlbs
+}
+
+// leaf2Descriptors returns the descriptor bytes of leaf 2.
+func (c *CPUInfo) leaf2Descriptors() []byte {
+	if c.maxFunc < 2 {
+		return nil
+	}
+	var desc []byte
+	eax, ebx, ecx, edx := c.src.CPUID(2)
+	// The low byte of eax is the number of times leaf 2 must be queried, which is always 1.
+	eax &^= 0xff
+	for _, reg := range []uint32{eax, ebx, ecx, edx} {
+		// Registers with bit 31 set contain no descriptors.
+		if reg&(1<<31) != 0 {
+			continue
+		}
+		for ; reg != 0; reg >>= 8 {
+			if d := byte(reg); d != 0 {
+				desc = append(desc, d)
+			}
+		}
+	}
+	return desc
+}
+
+// leaf2TLBs contains the TLBs of the leaf 2 descriptors.
+// Descriptors not listed describe caches or prefetching.
+var leaf2TLBs = map[byte][]TLBInfo{
+	0x01: {{Level: 1, Type: TLBTypeInstruction, PageSizes: Page4K, Entries: 32, Ways: 4}},
+	0x02: {{Level: 1, Type: TLBTypeInstruction, PageSizes: Page4M, Entries: 2, Ways: 2, FullyAssociative: true}},
+	0x03: {{Level: 1, Type: TLBTypeData, PageSizes: Page4K, Entries: 64, Ways: 4}},
+	0x04: {{Level: 1, Type: TLBTypeData, PageSizes: Page4M, Entries: 8, Ways: 4}},
+	0x05: {{Level: 1, Type: TLBTypeData, PageSizes: Page4M, Entries: 32, Ways: 4}},
+	0x0b: {{Level: 1, Type: TLBTypeInstruction, PageSizes: Page4M, Entries: 4, Ways: 4}},
+	0x4f: {{Level: 1, Type: TLBTypeInstruction, PageSizes: Page4K, Entries: 32}},
+	0x50: {{Level: 1, Type: TLBTypeInstruction, PageSizes: Page4K | Page2M | Page4M, Entries: 64}},
+	0x51: {{Level: 1, Type: TLBTypeInstruction, PageSizes: Page4K | Page2M | Page4M, Entries: 128}},
+	0x52: {{Level: 1, Type: TLBTypeInstruction, PageSizes: Page4K | Page2M | Page4M, Entries: 256}},
+	0x55: {{Level: 1, Type: TLBTypeInstruction, PageSizes: Page2M | Page4M, Entries: 7, Ways: 7, FullyAssociative: true}},
+	0x56: {{Level: 1, Type: TLBTypeData, PageSizes: Page4M, Entries: 16, Ways: 4}},
+	0x57: {{Level: 1, Type: TLBTypeData, PageSizes: Page4K, Entries: 16, Ways: 4}},
+	0x59: {{Level: 1, Type: TLBTypeData, PageSizes: Page4K, Entries: 16, Ways: 16, FullyAssociative: true}},
+	0x5a: {{Level: 1, Type: TLBTypeData, PageSizes: Page2M | Page4M, Entries: 32, Ways: 4}},
+	0x5b: {{Level: 1, Type: TLBTypeData, PageSizes: Page4K | Page4M, Entries: 64}},
+	0x5c: {{Level: 1, Type: TLBTypeData, PageSizes: Page4K | Page4M, Entries: 128}},
+	0x5d: {{Level: 1, Type: TLBTypeData, PageSizes: Page4K | Page4M, Entries: 256}},
+	0x61: {{Level: 1, Type: TLBTypeInstruction, PageSizes: Page4K, Entries: 48, Ways: 48, FullyAssociative: true}},
+	0x63: {
+		{Level: 1, Type: TLBTypeData, PageSizes: Page2M | Page4M, Entries: 32, Ways: 4},
+		{Level: 1, Type: TLBTypeData, PageSizes: Page1G, Entries: 4, Ways: 4},
+	},
+	0x64: {{Level: 1, Type: TLBTypeData, PageSizes: Page4K, Entries: 512, Ways: 4}},
+	0x6a: {{Level: 1, Type: TLBTypeData, PageSizes: Page4K, Entries: 64, Ways: 8}},
+	0x6b: {{Level: 1, Type: TLBTypeData, PageSizes: Page4K, Entries: 256, Ways: 8}},
+	0x6c: {{Level: 1, Type: TLBTypeData, PageSizes: Page2M | Page4M, Entries: 128, Ways: 8}},
+	0x6d: {{Level: 1, Type: TLBTypeData, PageSizes: Page1G, Entries: 16, Ways: 16, FullyAssociative: true}},
+	0x76: {{Level: 1, Type: TLBTypeInstruction, PageSizes: Page2M | Page4M, Entries: 8, Ways: 8, FullyAssociative: true}},
+	0xa0: {{Level: 1, Type: TLBTypeData, PageSizes: Page4K, Entries: 32, Ways: 32, FullyAssociative: true}},
+	0xb0: {{Level: 1, Type: TLBTypeInstruction, PageSizes: Page4K, Entries: 128, Ways: 4}},
+	0xb1: {{Level: 1, Type: TLBTypeInstruction, PageSizes: Page2M, Entries: 8, Ways: 4}, {Level: 1, Type: TLBTypeInstruction, PageSizes: Page4M, Entries: 4, Ways: 4}},
+	0xb2: {{Level: 1, Type: TLBTypeInstruction, PageSizes: Page4K, Entries: 64, Ways: 4}},
+	0xb3: {{Level: 1, Type: TLBTypeData, PageSizes: Page4K, Entries: 128, Ways: 4}},
+	0xb4: {{Level: 1, Type: TLBTypeData, PageSizes: Page4K, Entries: 256, Ways: 4}},
+	0xb5: {{Level: 1, Type: TLBTypeInstruction, PageSizes: Page4K, Entries: 64, Ways: 8}},
+	0xb6: {{Level: 1, Type: TLBTypeInstruction, PageSizes: Page4K, Entries: 128, Ways: 8}},
+	0xba: {{Level: 1, Type: TLBTypeData, PageSizes: Page4K, Entries: 64, Ways: 4}},
+	0xc0: {{Level: 1, Type: TLBTypeData, PageSizes: Page4K | Page4M, Entries: 8, Ways: 4}},
+	0xc1: {{Level: 2, Type: TLBTypeUnified, PageSizes: Page4K | Page2M, Entries: 1024, Ways: 8}},
+	0xc2: {{Level: 1, Type: TLBTypeData, PageSizes: Page4K | Page2M, Entries: 16, Ways: 4}},
+	0xc3: {
+		{Level: 2, Type: TLBTypeUnified, PageSizes: Page4K | Page2M, Entries: 1536, Ways: 6},
+		{Level: 2, Type: TLBTypeUnified, PageSizes: Page1G, Entries: 16, Ways: 4},
+	},
+	0xc4: {{Level: 1, Type: TLBTypeData, PageSizes: Page2M | Page4M, Entries: 32, Ways: 4}},
+	0xca: {{Level: 2, Type: TLBTypeUnified, PageSizes: Page4K, Entries: 512, Ways: 4}},
+}
+
+// tlbsAMD reads the TLBs from AMD leaf 0x80000005, 0x80000006 and 0x80000019.
+func (c *CPUInfo) tlbsAMD() []TLBInfo {
+	var tlbs []TLBInfo
+	add := func(level int, typ TLBType, pages PageSizes, entries, ways int) {
+		if entries == 0 || ways == 0 {
+			return
+		}
+		t := TLBInfo{Level: level, Type: typ, PageSizes: pages, Entries: entries, SharedBy: c.ThreadsPerCore}
+		switch {
+		case ways == -1 || ways == entries:
+			t.Ways, t.FullyAssociative = entries, true
+		case ways > 0:
+			t.Ways = ways
+		}
+		tlbs = append(tlbs, t)
+	}
+	// L1 TLBs have the number of ways, with 0xff being fully associative,
+	// and 8 bit entry counts.
+	l1 := func(reg uint32, pages PageSizes) {
+		ways := func(v uint32) int {
+			if v == 0xff {
+				return -1
+			}
+			return int(v)
+		}
+		add(1, TLBTypeData, pages, int(reg>>16)&0xff, ways(reg>>24))
+		add(1, TLBTypeInstruction, pages, int(reg)&0xff, ways((reg>>8)&0xff))
+	}
+	// Other TLBs have encoded ways and 12 bit entry counts.
+	encoded := func(level int, reg uint32, pages PageSizes) {
+		add(level, TLBTypeData, pages, int(reg>>16)&0xfff, amdAssociativity[reg>>28])
+		add(level, TLBTypeInstruction, pages, int(reg)&0xfff, amdAssociativity[(reg>>12)&0xf])
+	}
+	if c.maxExFunc < 0x80000005 {
+		return nil
+	}
+	eax, ebx, _, _ := c.src.CPUID(0x80000005)
+	l1(ebx, Page4K)
+	l1(eax, Page2M|Page4M)
+	if c.maxExFunc >= 0x80000006 {
+		eax, ebx, _, _ = c.src.CPUID(0x80000006)
+		encoded(2, ebx, Page4K)
+		encoded(2, eax, Page2M|Page4M)
+	}
+	if c.maxExFunc >= 0x80000019 {
+		eax, ebx, _, _ = c.src.CPUID(0x80000019)
+		encoded(1, eax, Page1G)
+		encoded(2, ebx, Page1G)
+	}
+	return tlbs
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import (
+	"math/bits"
+	"sort"
+	"sync"
+)
+
+// TopologyType is a type of topology domain, like a core or a die.
+type TopologyType uint8
+
+// Topology domains, from the smallest to the largest.
+const (
+	TopologyUnknown  TopologyType = iota
+	TopologyCore                  // Logical CPUs of a core, which share the core with SMT
+	TopologyModule                // Cores sharing resources, like the L2 cache or a Bulldozer compute unit
+	TopologyTile                  // Intel tile
+	TopologyComplex               // AMD core complex (CCX), cores sharing an L3 cache
+	TopologyDie                   // Die, like an AMD core complex die (CCD) or a Zen 1 node
+	TopologyDieGroup              // Intel die group
+	TopologyPackage               // Physical package or socket
+)
+
+// String returns the name of the topology type.
+func (t TopologyType) String() string {
+	switch t {
+	case TopologyCore:
+		return "Core"
+	case TopologyModule:
+		return "Module"
+	case TopologyTile:
+		return "Tile"
+	case TopologyComplex:
+		return "Complex"
+	case TopologyDie:
+		return "Die"
+	case TopologyDieGroup:
+		return "DieGroup"
+	case TopologyPackage:
+		return "Package"
+	}
+	return "Unknown"
+}
+
+// TopologyLevel is a level of the x2APIC ID.
+type TopologyLevel struct {
+	Type TopologyType
+	// Shift is the number of low bits of the x2APIC ID that identify a logical CPU within the domain.
+	// The x2APIC ID shifted right by Shift identifies the instance of the domain.
+	Shift int
+	// LogicalCPUs is the number of logical CPUs in each instance of the domain.
+	// On CPUs without leaf 0xB this may be the number of addressable IDs,
+	// which can be higher than the number of logical CPUs.
+	LogicalCPUs int
+}
+
+// Topology describes how logical CPUs are grouped into cores, dies and packages.
+type Topology struct {
+	Levels          []TopologyLevel // Domains, from the smallest to the package. Nil if unknown
+	X2APICID        uint32          // x2APIC ID of the logical CPU detection ran on
+	NodeID          int             // AMD node ID from leaf 0x8000001E
+	NodesPerPackage int             // AMD nodes per package from leaf 0x8000001E. 0 if unknown
+
+	// LogicalCPUs and Packages are the number of online logical CPUs and packages in the system,
+	// as reported by the OS. They are 0 if unknown, and are not set by DetectFrom.
+	LogicalCPUs int
+	Packages    int
+}
+
+// Level returns the level of the domain type.
+func (t Topology) Level(typ TopologyType) (TopologyLevel, bool) {
+	for _, l := range t.Levels {
+		if l.Type == typ {
+			return l, true
+		}
+	}
+	return TopologyLevel{}, false
+}
+
+// ID returns the ID of the domain instance containing the logical CPU with the x2APIC ID.
+// IDs are unique in the system.
+func (t Topology) ID(typ TopologyType, x2APICID uint32) (uint32, bool) {
+	l, ok := t.Level(typ)
+	if !ok {
+		return 0, false
+	}
+	return x2APICID >> l.Shift, true
+}
+
+// ThreadsPerCore returns the number of logical CPUs per core, or 0 if unknown.
+func (t Topology) ThreadsPerCore() int {
+	l, _ := t.Level(TopologyCore)
+	return l.LogicalCPUs
+}
+
+// PerPackage returns the number of instances of the domain type in each package, or 0 if unknown.
+func (t Topology) PerPackage(typ TopologyType) int {
+	l, ok := t.Level(typ)
+	pkg, pkgOK := t.Level(TopologyPackage)
+	if !ok || !pkgOK || l.LogicalCPUs <= 0 {
+		return 0
+	}
+	return pkg.LogicalCPUs / l.LogicalCPUs
+}
+
+// System returns the number of instances of the domain type in the system, or 0 if unknown.
+// This is the number per package times the number of packages.
+func (t Topology) System(typ TopologyType) int {
+	return t.PerPackage(typ) * t.Packages
+}
+
+// setSystem sets the number of logical CPUs and packages reported by the OS.
+// If the number of packages is unknown, it is calculated from the logical CPUs per package.
+func (t *Topology) setSystem(logicalCPUs, packages int) {
+	t.LogicalCPUs, t.Packages = logicalCPUs, packages
+	if pkg, ok := t.Level(TopologyPackage); ok && packages == 0 && logicalCPUs > 0 && pkg.LogicalCPUs > 0 {
+		t.Packages = (logicalCPUs + pkg.LogicalCPUs - 1) / pkg.LogicalCPUs
+	}
+}
+
+// osTopologyOnce reads the totals reported by the OS once,
+// so detecting the CPU again does not read them again.
+var osTopologyOnce = sync.OnceValues(osTopology)
+
+// shiftFor returns the number of bits needed for n IDs.
+func shiftFor(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	return bits.Len(uint(n - 1))
+}
+
+// intelTopologyTypes contains the domains of the level types of leaf 0xB and 0x1F.
+var intelTopologyTypes = map[uint32]TopologyType{
+	2: TopologyCore,
+	3: TopologyModule,
+	4: TopologyTile,
+	5: TopologyDie,
+	6: TopologyDieGroup,
+}
+
+// amdTopologyTypes contains the domains of the level types of leaf 0x80000026.
+var amdTopologyTypes = map[uint32]TopologyType{
+	1: TopologyCore,
+	2: TopologyComplex,
+	3: TopologyDie,
+	4: TopologyPackage,
+}
+
+// topology will detect the topology of the CPU.
+func (c *CPUInfo) topology() Topology {
+	var t Topology
+	if c.maxFunc >= 1 {
+		_, ebx, _, _ := c.src.CPUID(1)
+		t.X2APICID = ebx >> 24
+	}
+	amd := c.VendorID == AMD || c.VendorID == Hygon
+	if amd && c.maxExFunc >= 0x8000001e && c.Has(TOPEXT) {
+		eax, ebx, ecx, _ := c.src.CPUID(0x8000001e)
+		t.X2APICID = eax
+		t.NodeID = int(ecx & 0xff)
+		t.NodesPerPackage = int(ecx>>8)&7 + 1
+		// Family 0x15 reports the cores per compute unit.
+		if n := int(ebx>>8)&0xff + 1; c.Family == 0x15 && n > 1 {
+			t.Levels = append(t.Levels, TopologyLevel{Type: TopologyModule, Shift: shiftFor(n), LogicalCPUs: n})
+		}
+	}
+
+	var levels []TopologyLevel
+	if amd && c.maxExFunc >= 0x80000026 {
+		levels = c.topologyLevels(0x80000026, &t.X2APICID)
+	}
+	if len(levels) == 0 && c.maxFunc >= 0x1f {
+		levels = c.topologyLevels(0x1f, &t.X2APICID)
+	}
+	if len(levels) == 0 && c.maxFunc >= 0xb {
+		levels = c.topologyLevels(0xb, &t.X2APICID)
+	}
+	if len(levels) == 0 {
+		levels = c.topologyLegacy()
+	}
+	t.Levels = append(t.Levels, levels...)
+
+	if amd {
+		c.addAMDDomains(&t)
+	}
+	sort.SliceStable(t.Levels, func(i, j int) bool { return t.Levels[i].Type < t.Levels[j].Type })
+	return t
+}
+
+// topologyLevels reads the levels of leaf 0xB, 0x1F or 0x80000026.
+// Levels of leaf 0xB and 0x1F identify the domain of the next level,
+// while levels of leaf 0x80000026 identify their own domain.
+// x2APICID is set if any levels are found.
+func (c *CPUInfo) topologyLevels(leaf uint32, x2APICID *uint32) []TopologyLevel {
+	var levels []TopologyLevel
+	var types []uint32
+	for i := uint32(0); i < 16; i++ {
+		eax, ebx, ecx, edx := c.src.CPUIDEX(leaf, i)
+		typ := (ecx >> 8) & 0xff
+		if typ == 0 || ebx&0xffff == 0 {
+			break
+		}
+		*x2APICID = edx
+		types = append(types, typ)
+		levels = append(levels, TopologyLevel{Shift: int(eax & 0x1f), LogicalCPUs: int(ebx & 0xffff)})
+	}
+	out := levels[:0]
+	for i, l := range levels {
+		switch {
+		case leaf == 0x80000026:
+			l.Type = amdTopologyTypes[types[i]]
+		case i+1 < len(levels):
+			l.Type = intelTopologyTypes[types[i+1]]
+		default:
+			// The last level identifies the package.
+			l.Type = TopologyPackage
+		}
+		if l.Type != TopologyUnknown {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// topologyLegacy returns the core and package levels of CPUs without leaf 0xB.
+// The logical CPUs per package is the number of addressable IDs.
+func (c *CPUInfo) topologyLegacy() []TopologyLevel {
+	if c.maxFunc < 1 {
+		return nil
+	}
+	_, ebx, _, edx := c.src.CPUID(1)
+	logical := 1
+	if edx&(1<<28) != 0 {
+		logical = max(int(ebx>>16)&0xff, 1)
+	}
+	pkgShift := shiftFor(logical)
+	if (c.VendorID == AMD || c.VendorID == Hygon) && c.maxExFunc >= 0x80000008 {
+		_, _, ecx, _ := c.src.CPUID(0x80000008)
+		logical = int(ecx&0xff) + 1
+		pkgShift = shiftFor(logical)
+		if size := int(ecx>>12) & 0xf; size > 0 {
+			pkgShift = size
+		}
+	}
+	return []TopologyLevel{
+		{Type: TopologyCore, Shift: shiftFor(c.ThreadsPerCore), LogicalCPUs: c.ThreadsPerCore},
+		{Type: TopologyPackage, Shift: pkgShift, LogicalCPUs: logical},
+	}
+}
+
+// addAMDDomains adds core complexes of Zen CPUs from the L3 cache sharing,
+// and dies from the nodes of leaf 0x8000001E, if not already known.
+func (c *CPUInfo) addAMDDomains(t *Topology) {
+	pkg, ok := t.Level(TopologyPackage)
+	if !ok {
+		return
+	}
+	if _, ok := t.Level(TopologyComplex); !ok && c.Family >= 0x17 {
+		for _, ci := range c.Caches {
+			if ci.Level == 3 && ci.SharedBy > 0 && ci.SharedBy < pkg.LogicalCPUs {
+				t.Levels = append(t.Levels, TopologyLevel{Type: TopologyComplex, Shift: shiftFor(ci.SharedBy), LogicalCPUs: ci.SharedBy})
+				break
+			}
+		}
+	}
+	if _, ok := t.Level(TopologyDie); !ok && t.NodesPerPackage > 1 {
+		t.Levels = append(t.Levels, TopologyLevel{
+			Type:        TopologyDie,
+			Shift:       pkg.Shift - shiftFor(t.NodesPerPackage),
+			LogicalCPUs: pkg.LogicalCPUs / t.NodesPerPackage,
+		})
+	}
+}
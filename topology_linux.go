@@ -0,0 +1,28 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+package cpuid
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// osTopology returns the number of online logical CPUs and packages reported by the OS.
+func osTopology() (logicalCPUs, packages int) {
+	if b, err := os.ReadFile("/sys/devices/system/cpu/online"); err == nil {
+		logicalCPUs = cpuListLen(strings.TrimSpace(string(b)))
+	}
+	ids, _ := filepath.Glob("/sys/devices/system/cpu/cpu[0-9]*/topology/physical_package_id")
+	seen := make(map[string]bool, len(ids))
+	for _, name := range ids {
+		if b, err := os.ReadFile(name); err == nil {
+			seen[strings.TrimSpace(string(b))] = true
+		}
+	}
+	if logicalCPUs == 0 {
+		logicalCPUs = runtime.NumCPU()
+	}
+	return logicalCPUs, len(seen)
+}
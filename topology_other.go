@@ -0,0 +1,14 @@
+// Copyright (c) 2026 Klaus Post, released under MIT License. See LICENSE file.
+
+//go:build !linux
+// +build !linux
+
+package cpuid
+
+import "runtime"
+
+// osTopology returns the number of logical CPUs reported by the OS.
+// The number of packages is not known.
+func osTopology() (logicalCPUs, packages int) {
+	return runtime.NumCPU(), 0
+}